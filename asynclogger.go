@@ -0,0 +1,169 @@
+package output
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// OverflowPolicy specifies what an AsyncLogger does with a log message when
+	// its queue is full.
+	OverflowPolicy uint8
+
+	// AsyncLogger is a Logger that queues log messages on a bounded channel and
+	// passes them to another Logger from a background goroutine, so that a slow
+	// Logger does not block its callers.
+	//
+	// Panic and fatal log messages are not queued: the AsyncLogger first waits
+	// for every queued message to be written, and then passes the panic or
+	// fatal log message to the other Logger synchronously, so that nothing is
+	// lost if that Logger then panics or exits the program.
+	//
+	// Call Close to drain the queue and stop the background goroutine; log
+	// messages received after Close are passed to the other Logger
+	// synchronously.
+	AsyncLogger struct {
+		logger  Logger
+		policy  OverflowPolicy
+		queue   chan asyncRequest
+		lock    sync.RWMutex
+		closed  bool
+		dropped atomic.Uint64
+		done    chan struct{}
+	}
+
+	// asyncRequest is either a log message or, if flushed is not nil, a
+	// request to close flushed once everything queued before it has been
+	// written
+	asyncRequest struct {
+		entry   LogEntry
+		flushed chan struct{}
+	}
+)
+
+// These are the OverflowPolicy values.
+const (
+	// BlockWhenFull makes the caller wait for room in the queue.
+	BlockWhenFull OverflowPolicy = iota
+	// DropWhenFull discards the log message, and counts it as dropped.
+	DropWhenFull
+)
+
+// NewAsyncLogger returns an AsyncLogger that passes log messages to l; its
+// queue holds up to capacity log messages.
+func NewAsyncLogger(l Logger, capacity int, policy OverflowPolicy) *AsyncLogger {
+	al := &AsyncLogger{
+		logger: l,
+		policy: policy,
+		queue:  make(chan asyncRequest, max(capacity, 0)),
+		done:   make(chan struct{}),
+	}
+	go al.run()
+	return al
+}
+
+func (al *AsyncLogger) run() {
+	defer close(al.done)
+	for request := range al.queue {
+		if request.flushed != nil {
+			close(request.flushed)
+			continue
+		}
+		logAt(al.logger, request.entry.Level, request.entry.Msg, request.entry.Fields)
+	}
+}
+
+// Trace queues a trace log message.
+func (al *AsyncLogger) Trace(msg string, fields map[string]any) {
+	al.enqueue(Trace, msg, fields)
+}
+
+// Debug queues a debug log message.
+func (al *AsyncLogger) Debug(msg string, fields map[string]any) {
+	al.enqueue(Debug, msg, fields)
+}
+
+// Info queues an info log message.
+func (al *AsyncLogger) Info(msg string, fields map[string]any) {
+	al.enqueue(Info, msg, fields)
+}
+
+// Warning queues a warning log message.
+func (al *AsyncLogger) Warning(msg string, fields map[string]any) {
+	al.enqueue(Warning, msg, fields)
+}
+
+// Error queues an error log message.
+func (al *AsyncLogger) Error(msg string, fields map[string]any) {
+	al.enqueue(Error, msg, fields)
+}
+
+// Panic flushes the queue and then passes the panic log message to the other
+// Logger.
+func (al *AsyncLogger) Panic(msg string, fields map[string]any) {
+	al.Flush()
+	al.logger.Panic(msg, fields)
+}
+
+// Fatal flushes the queue and then passes the fatal log message to the other
+// Logger.
+func (al *AsyncLogger) Fatal(msg string, fields map[string]any) {
+	al.Flush()
+	al.logger.Fatal(msg, fields)
+}
+
+func (al *AsyncLogger) enqueue(l Level, msg string, fields map[string]any) {
+	al.lock.RLock()
+	defer al.lock.RUnlock()
+	if al.closed {
+		logAt(al.logger, l, msg, fields)
+		return
+	}
+	// the caller may reuse fields as soon as this function returns
+	request := asyncRequest{entry: LogEntry{Level: l, Msg: msg, Fields: maps.Clone(fields)}}
+	if al.policy == DropWhenFull {
+		select {
+		case al.queue <- request:
+		default:
+			al.dropped.Add(1)
+		}
+		return
+	}
+	al.queue <- request
+}
+
+// Flush waits until every log message queued before the call has been passed
+// to the other Logger.
+func (al *AsyncLogger) Flush() {
+	al.lock.RLock()
+	if al.closed {
+		al.lock.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	al.queue <- asyncRequest{flushed: flushed}
+	al.lock.RUnlock()
+	<-flushed
+}
+
+// Dropped returns the number of log messages discarded because the queue was
+// full.
+func (al *AsyncLogger) Dropped() uint64 {
+	return al.dropped.Load()
+}
+
+// Close drains the queue and stops the background goroutine; it always returns
+// nil.
+func (al *AsyncLogger) Close() error {
+	al.lock.Lock()
+	if al.closed {
+		al.lock.Unlock()
+		return nil
+	}
+	al.closed = true
+	close(al.queue)
+	al.lock.Unlock()
+	<-al.done
+	return nil
+}
@@ -0,0 +1,113 @@
+package output_test
+
+import (
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+// gatedLogger blocks each log message until the gate allows it through
+type gatedLogger struct {
+	*output.RecordingLogger
+	gate chan struct{}
+}
+
+func (gl gatedLogger) Info(msg string, fields map[string]any) {
+	<-gl.gate
+	gl.RecordingLogger.Info(msg, fields)
+}
+
+func TestAsyncLogger_Order(t *testing.T) {
+	rl := output.NewRecordingLogger()
+	al := output.NewAsyncLogger(rl, 2, output.BlockWhenFull)
+	al.Trace("t", nil)
+	al.Debug("d", nil)
+	al.Info("i", map[string]any{"k": "v"})
+	al.Warning("w", nil)
+	al.Error("e", nil)
+	al.Flush()
+	want := "level='trace'  msg='t'\n" +
+		"level='debug'  msg='d'\n" +
+		"level='info' k='v' msg='i'\n" +
+		"level='warning'  msg='w'\n" +
+		"level='error'  msg='e'\n"
+	if got := rl.String(); got != want {
+		t.Errorf("AsyncLogger wrote %q, want %q", got, want)
+	}
+	if err := al.Close(); err != nil {
+		t.Errorf("AsyncLogger.Close() error = %v", err)
+	}
+	al.Info("after close", nil)
+	al.Flush()
+	_ = al.Close()
+	if got := rl.String(); got != want+"level='info'  msg='after close'\n" {
+		t.Errorf("AsyncLogger wrote %q after Close", got)
+	}
+	if got := al.Dropped(); got != 0 {
+		t.Errorf("AsyncLogger.Dropped() = %d, want 0", got)
+	}
+}
+
+func TestAsyncLogger_Drop(t *testing.T) {
+	gl := gatedLogger{RecordingLogger: output.NewRecordingLogger(), gate: make(chan struct{})}
+	al := output.NewAsyncLogger(gl, 1, output.DropWhenFull)
+	al.Info("first", nil) // taken by the background goroutine, which waits at the gate
+	// wait until the background goroutine has the first message, so that the
+	// queue is empty again
+	gl.gate <- struct{}{}
+	al.Info("second", nil) // now held by the background goroutine
+	for al.Dropped() == 0 {
+		al.Info("extra", nil) // fills the queue, then is dropped
+	}
+	close(gl.gate)
+	if err := al.Close(); err != nil {
+		t.Errorf("AsyncLogger.Close() error = %v", err)
+	}
+	got := gl.String()
+	if want := "level='info'  msg='first'\nlevel='info'  msg='second'\n"; got[:len(want)] != want {
+		t.Errorf("AsyncLogger wrote %q, want prefix %q", got, want)
+	}
+	if al.Dropped() == 0 {
+		t.Errorf("AsyncLogger.Dropped() = 0")
+	}
+}
+
+func TestAsyncLogger_Terminal(t *testing.T) {
+	ml := output.NewMockLogger(t).
+		Expect(output.Info, "queued", nil).
+		Expect(output.Fatal, "bye", nil).
+		Expect(output.Info, "queued again", nil).
+		Expect(output.Panic, "boom", nil).
+		PanicOnPanic()
+	al := output.NewAsyncLogger(ml, 10, output.BlockWhenFull)
+	al.Info("queued", nil)
+	al.Fatal("bye", nil)
+	al.Info("queued again", nil)
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("AsyncLogger.Panic() recovered %v", r)
+			}
+		}()
+		al.Panic("boom", nil)
+	}()
+	_ = al.Close()
+	ml.AssertExpectations()
+}
+
+func TestAsyncLogger_FieldsCopied(t *testing.T) {
+	gl := gatedLogger{RecordingLogger: output.NewRecordingLogger(), gate: make(chan struct{})}
+	al := output.NewAsyncLogger(gl, 2, output.BlockWhenFull)
+	fields := map[string]any{"attempt": 1}
+	al.Info("retry", fields)
+	// the caller reuses its map while the message is still queued
+	fields["attempt"] = 2
+	delete(fields, "attempt")
+	close(gl.gate)
+	if err := al.Close(); err != nil {
+		t.Errorf("AsyncLogger.Close() error = %v", err)
+	}
+	if got, want := gl.String(), "level='info' attempt='1' msg='retry'\n"; got != want {
+		t.Errorf("AsyncLogger wrote %q, want %q", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/mattn/go-isatty"
 )
@@ -12,34 +13,48 @@ type (
 	// Level is used to specify log levels for Bus.Log().
 	Level uint32
 
-	// Bus defines a set of functions for writing console messages and error messages, and for providing access to the
-	// console writer and the error writer, and a Logger instance; its primary use is to simplify how application code
-	// handles console, error, and logged output, and its secondary use is to make it easy to test output writing.
-	Bus interface {
+	// LogSink is the part of Bus that logs messages.
+	LogSink interface {
 		// Log logs a message and map of fields at a specified log level.
 		Log(Level, string, map[string]any)
+	}
+
+	// ConsolePrinter is the part of Bus that writes console output.
+	ConsolePrinter interface {
 		// ConsolePrintf prints a message with arguments to the error channel
 		ConsolePrintf(string, ...any)
 		// ConsolePrintln prints a message to the error channel, terminated by a newline
 		ConsolePrintln(string)
+		// ConsoleWriter returns a writer for console output.
+		ConsoleWriter() io.Writer
+		// IsConsoleTTY returns whether the console writer is a TTY
+		IsConsoleTTY() bool
+	}
+
+	// ErrorPrinter is the part of Bus that writes error output.
+	ErrorPrinter interface {
 		// ErrorPrintf prints a message with arguments to the error channel
 		ErrorPrintf(string, ...any)
 		// ErrorPrintln prints a message to the error channel, terminated by a newline
 		ErrorPrintln(string)
-		// ConsoleWriter returns a writer for console output.
-		ConsoleWriter() io.Writer
 		// ErrorWriter returns a writer for error output.
 		ErrorWriter() io.Writer
-		// IsConsoleTTY returns whether the console writer is a TTY
-		IsConsoleTTY() bool
 		// IsErrorTTY returns whether the error writer is a TTY
 		IsErrorTTY() bool
+	}
+
+	// Indenter is the part of Bus that manages the tab setting.
+	Indenter interface {
 		// Tab returns the current tab setting (number of spaces)
 		Tab() uint8
 		// IncrementTab increases the current tab setting up to the max uint8 value
 		IncrementTab(uint8)
 		// DecrementTab decreases the current tab setting; will not go below 0
 		DecrementTab(uint8)
+	}
+
+	// Lister is the part of Bus that manages console and error lists.
+	Lister interface {
 		// BeginConsoleList initiates console listing
 		BeginConsoleList(bool)
 		// EndConsoleList terminates console listing
@@ -54,6 +69,20 @@ type (
 		ErrorListDecorator() *ListDecorator
 	}
 
+	// Bus defines a set of functions for writing console messages and error messages, and for providing access to the
+	// console writer and the error writer, and a Logger instance; its primary use is to simplify how application code
+	// handles console, error, and logged output, and its secondary use is to make it easy to test output writing.
+	//
+	// Bus is composed of smaller interfaces, so that code needing only part of it can say so; to implement only part
+	// of a Bus, see NewPartialBus.
+	Bus interface {
+		LogSink
+		ConsolePrinter
+		ErrorPrinter
+		Indenter
+		Lister
+	}
+
 	// Logger defines a set of functions for writing to a log at various log levels
 	Logger interface {
 		Trace(msg string, fields map[string]any)
@@ -75,6 +104,11 @@ type (
 		tab                  uint8
 		consoleListDecorator *ListDecorator
 		errorListDecorator   *ListDecorator
+		listBullet           string
+		interceptors         []Interceptor
+		logLevel             *Level
+		subscribers          subscribers
+		tally                Tally
 	}
 )
 
@@ -89,19 +123,73 @@ const (
 	Trace
 )
 
+var levelNames = []string{
+	Fatal:   "fatal",
+	Panic:   "panic",
+	Error:   "error",
+	Warning: "warning",
+	Info:    "info",
+	Debug:   "debug",
+	Trace:   "trace",
+}
+
+// String returns the lower-case name of the level, e.g., "warning"; the names
+// match those written by RecordingLogger.
+func (l Level) String() string {
+	if int(l) < len(levelNames) {
+		return levelNames[l]
+	}
+	return fmt.Sprintf("Level(%d)", uint32(l))
+}
+
+// ParseLevel returns the Level whose name (as returned by Level.String()) matches
+// the specified string, ignoring case; "warn" is accepted as a synonym for
+// "warning".
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warn" {
+		return Warning, nil
+	}
+	for l, levelName := range levelNames {
+		if name == levelName {
+			return Level(l), nil
+		}
+	}
+	return 0, fmt.Errorf("output: unknown log level %q", s)
+}
+
 // NewDefaultBus returns an implementation of Bus that writes console messages to stdout and error messages to stderr.
+// It is equivalent to NewBus(WithLogger(l)).
 func NewDefaultBus(l Logger) Bus {
-	return NewCustomBus(os.Stdout, os.Stderr, l)
+	return NewBus(WithLogger(l))
 }
 
 // vars so testing can replace
 var (
 	isTerminal       = isatty.IsTerminal
 	isCygwinTerminal = isatty.IsCygwinTerminal
+	getTerminalSize  = platformTerminalSize
 )
 
+// underlyingFile returns the file that w is, or that the writer it wraps (as
+// reported by an Unwrap() io.Writer function) is; it returns nil if there is
+// no such file
+func underlyingFile(w io.Writer) *os.File {
+	for {
+		u, ok := w.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	f, _ := w.(*os.File)
+	return f
+}
+
+// isTTY determines whether w, or the writer it wraps (as reported by an
+// Unwrap() io.Writer function), is a terminal
 func isTTY(w io.Writer) (b bool) {
-	if f, ok := w.(*os.File); ok {
+	if f := underlyingFile(w); f != nil {
 		fd := f.Fd()
 		b = isTerminal(fd) || isCygwinTerminal(fd)
 	}
@@ -109,48 +197,82 @@ func isTTY(w io.Writer) (b bool) {
 }
 
 // NewCustomBus returns an implementation of Bus that lets the caller specify the console and error writers and the
-// Logger.
+// Logger. It is equivalent to NewBus(WithConsoleWriter(c), WithErrorWriter(e), WithLogger(l)).
 func NewCustomBus(c, e io.Writer, l Logger) Bus {
-	return &bus{
-		consoleWriter:        c,
-		errorWriter:          e,
-		logger:               l,
-		performWrites:        true,
-		consoleTTY:           isTTY(c),
-		errorTTY:             isTTY(e),
-		tab:                  0,
-		consoleListDecorator: newListDecorator(false, false),
-		errorListDecorator:   newListDecorator(false, false),
-	}
+	return NewBus(WithConsoleWriter(c), WithErrorWriter(e), WithLogger(l))
 }
 
 // Log logs a message and map of fields at a specified log level.
 func (b *bus) Log(l Level, msg string, args map[string]any) {
 	if b.performWrites {
-		switch l {
-		case Trace:
-			b.logger.Trace(msg, args)
-		case Debug:
-			b.logger.Debug(msg, args)
-		case Info:
-			b.logger.Info(msg, args)
-		case Warning:
-			b.logger.Warning(msg, args)
-		case Error:
-			b.logger.Error(msg, args)
-		case Panic:
-			b.logger.Panic(msg, args)
-		case Fatal:
-			b.logger.Fatal(msg, args)
-		default:
+		b.tally.countLog(l, msg, args)
+		if b.logs(l) {
+			b.emit(Event{Channel: LogChannel, Text: msg, Level: l, Fields: args})
+		}
+	}
+}
+
+// logs returns whether the Bus passes log messages at the specified level to
+// its Logger, as opposed to discarding them; invalid levels are passed along,
+// so that they are reported
+func (b *bus) logs(l Level) bool {
+	return b.logLevel == nil || l <= *b.logLevel || l > Trace
+}
+
+// deliver writes an Event that has passed through the interceptors
+func (b *bus) deliver(e Event) {
+	switch e.Channel {
+	case ConsoleChannel:
+		_, _ = io.WriteString(b.consoleWriter, e.Text)
+	case ErrorChannel:
+		_, _ = io.WriteString(b.errorWriter, e.Text)
+	case LogChannel:
+		if !b.logs(e.Level) {
+			return
+		}
+		if e.Level <= Panic {
+			// the Logger may panic or exit the program, so notify first
+			b.subscribers.notify(e)
+			logAt(b.logger, e.Level, e.Text, e.Fields)
+			return
+		}
+		if !logAt(b.logger, e.Level, e.Text, e.Fields) {
 			b.ErrorPrintf(
 				"Programming error: call to bus.Log() with invalid level value %d; message: '%s', args: '%v'.\n",
-				l,
-				msg,
-				args,
+				e.Level,
+				e.Text,
+				e.Fields,
 			)
+			return
 		}
+	default:
+		return
+	}
+	b.subscribers.notify(e)
+}
+
+// logAt calls the Logger function corresponding to the specified level; it
+// returns false, having called nothing, if the level is invalid.
+func logAt(logger Logger, l Level, msg string, fields map[string]any) bool {
+	switch l {
+	case Trace:
+		logger.Trace(msg, fields)
+	case Debug:
+		logger.Debug(msg, fields)
+	case Info:
+		logger.Info(msg, fields)
+	case Warning:
+		logger.Warning(msg, fields)
+	case Error:
+		logger.Error(msg, fields)
+	case Panic:
+		logger.Panic(msg, fields)
+	case Fatal:
+		logger.Fatal(msg, fields)
+	default:
+		return false
 	}
+	return true
 }
 
 // ConsoleWriter returns a writer for console output.
@@ -166,49 +288,53 @@ func (b *bus) ErrorWriter() io.Writer {
 // ErrorPrintln prints a message to the error channel, terminated by a newline
 func (b *bus) ErrorPrintln(msg string) {
 	if b.performWrites {
-		doPrintln(b.errorWriter, b.errorListDecorator, msg)
+		b.tally.countErrorOutput(msg)
+		b.emit(Event{Channel: ErrorChannel, Text: doSprintln(b.errorListDecorator, msg)})
 	}
 }
 
 // ErrorPrintf prints a message with arguments to the error channel
 func (b *bus) ErrorPrintf(format string, args ...any) {
 	if b.performWrites {
-		doPrintf(b.errorWriter, b.errorListDecorator, format, args...)
+		msg := fmt.Sprintf(format, args...)
+		b.tally.countErrorOutput(msg)
+		b.emit(Event{Channel: ErrorChannel, Text: decorated(b.errorListDecorator, msg)})
 	}
 }
 
 // ConsolePrintln prints a message to the error channel, terminated by a newline
 func (b *bus) ConsolePrintln(msg string) {
 	if b.performWrites {
-		writeTabbedContent(b.consoleWriter, b.tab, doSprintln(b.consoleListDecorator, msg))
+		b.emit(Event{Channel: ConsoleChannel, Text: tabbedContent(b.tab, doSprintln(b.consoleListDecorator, msg))})
 	}
 }
 
 // ConsolePrintf prints a message with arguments to the error channel
 func (b *bus) ConsolePrintf(format string, args ...any) {
 	if b.performWrites {
-		writeTabbedContent(b.consoleWriter, b.tab, doSprintf(b.consoleListDecorator, format, args...))
+		b.emit(Event{
+			Channel: ConsoleChannel,
+			Text:    tabbedContent(b.tab, doSprintf(b.consoleListDecorator, format, args...)),
+		})
 	}
 }
 
-func doPrintf(w io.Writer, decorator *ListDecorator, format string, args ...any) {
-	_, _ = fmt.Fprint(w, doSprintf(decorator, format, args...))
-}
-
 func doSprintf(decorator *ListDecorator, format string, args ...any) string {
-	return fmt.Sprintf("%s%s", decorator.Decorator(), fmt.Sprintf(format, args...))
+	return decorated(decorator, fmt.Sprintf(format, args...))
 }
 
-func doPrintln(w io.Writer, decorator *ListDecorator, msg string) {
-	_, _ = fmt.Fprint(w, doSprintln(decorator, msg))
+// decorated returns msg, already formatted, preceded by the decorator's next
+// list item decoration, if any
+func decorated(decorator *ListDecorator, msg string) string {
+	return decorator.Decorator() + msg
 }
 
 func doSprintln(decorator *ListDecorator, msg string) string {
 	return fmt.Sprintf("%s%s\n", decorator.Decorator(), msg)
 }
 
-func writeTabbedContent(w io.Writer, tab uint8, content string) {
-	fmt.Fprintf(w, "%*s%s", tab, "", content)
+func tabbedContent(tab uint8, content string) string {
+	return fmt.Sprintf("%*s%s", tab, "", content)
 }
 
 // IncrementTab increments the tab setting by the specified number of spaces
@@ -230,7 +356,7 @@ func (b *bus) DecrementTab(t uint8) {
 
 // BeginConsoleList initiates console listing
 func (b *bus) BeginConsoleList(numeric bool) {
-	b.consoleListDecorator = newListDecorator(true, numeric)
+	b.consoleListDecorator = newListDecorator(true, numeric).withBullet(b.listBullet)
 }
 
 // EndConsoleList terminates console listing
@@ -245,7 +371,7 @@ func (b *bus) ConsoleListDecorator() *ListDecorator {
 
 // BeginErrorList initiates error listing
 func (b *bus) BeginErrorList(numeric bool) {
-	b.errorListDecorator = newListDecorator(true, numeric)
+	b.errorListDecorator = newListDecorator(true, numeric).withBullet(b.listBullet)
 }
 
 // EndErrorList terminates error listing
@@ -279,3 +405,29 @@ func (b *bus) IsConsoleTTY() bool {
 func (b *bus) IsErrorTTY() bool {
 	return b.errorTTY
 }
+
+// ConsoleTerminalSize returns the width and height of the console terminal; ok
+// is false if the console writer is not a TTY, or if its size cannot be
+// determined
+func (b *bus) ConsoleTerminalSize() (width, height int, ok bool) {
+	return fileTerminalSize(b.consoleTTY, b.consoleWriter)
+}
+
+// ErrorTerminalSize returns the width and height of the error terminal; ok is
+// false if the error writer is not a TTY, or if its size cannot be determined
+func (b *bus) ErrorTerminalSize() (width, height int, ok bool) {
+	return fileTerminalSize(b.errorTTY, b.errorWriter)
+}
+
+// fileTerminalSize returns the size of the terminal behind w; the size is
+// looked up on each call, as the user may resize the terminal at any time
+func fileTerminalSize(tty bool, w io.Writer) (width, height int, ok bool) {
+	if !tty {
+		return 0, 0, false
+	}
+	f := underlyingFile(w)
+	if f == nil {
+		return 0, 0, false
+	}
+	return getTerminalSize(f.Fd())
+}
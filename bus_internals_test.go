@@ -537,6 +537,62 @@ func Test_isTTY(t *testing.T) {
 	}
 }
 
+func Test_bus_TerminalSize(t *testing.T) {
+	oldGetTerminalSize := getTerminalSize
+	defer func() {
+		getTerminalSize = oldGetTerminalSize
+	}()
+	getTerminalSize = func(fd uintptr) (width, height int, ok bool) {
+		if fd == os.Stderr.Fd() {
+			return 0, 0, false
+		}
+		return 120, 40, true
+	}
+	tests := map[string]struct {
+		b          *bus
+		wantWidth  int
+		wantHeight int
+		wantOk     bool
+	}{
+		"nil bus": {b: NewNilBus().(*bus)},
+		"not a tty": {
+			b: &bus{consoleWriter: os.Stdout, errorWriter: os.Stdout},
+		},
+		"tty, but not a file": {
+			b: &bus{consoleWriter: &bytes.Buffer{}, errorWriter: &bytes.Buffer{}, consoleTTY: true, errorTTY: true},
+		},
+		"size unknown": {
+			b: &bus{consoleWriter: os.Stderr, errorWriter: os.Stderr, consoleTTY: true, errorTTY: true},
+		},
+		"wrapped tty": {
+			b: &bus{
+				consoleWriter: NewPrefixWriter(os.Stdout, LinePrefix{}),
+				errorWriter:   NewPrefixWriter(os.Stdout, LinePrefix{}),
+				consoleTTY:    true,
+				errorTTY:      true,
+			},
+			wantWidth:  120,
+			wantHeight: 40,
+			wantOk:     true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sizer TerminalSizer = tt.b
+			gotWidth, gotHeight, gotOk := sizer.ConsoleTerminalSize()
+			if gotWidth != tt.wantWidth || gotHeight != tt.wantHeight || gotOk != tt.wantOk {
+				t.Errorf("bus.ConsoleTerminalSize() = %d, %d, %t, want %d, %d, %t",
+					gotWidth, gotHeight, gotOk, tt.wantWidth, tt.wantHeight, tt.wantOk)
+			}
+			gotWidth, gotHeight, gotOk = sizer.ErrorTerminalSize()
+			if gotWidth != tt.wantWidth || gotHeight != tt.wantHeight || gotOk != tt.wantOk {
+				t.Errorf("bus.ErrorTerminalSize() = %d, %d, %t, want %d, %d, %t",
+					gotWidth, gotHeight, gotOk, tt.wantWidth, tt.wantHeight, tt.wantOk)
+			}
+		})
+	}
+}
+
 func Test_bus_IncrementTab(t *testing.T) {
 	tests := map[string]struct {
 		initialTab uint8
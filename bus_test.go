@@ -93,3 +93,46 @@ func Test_bus_BeginErrorList(t *testing.T) {
 		})
 	}
 }
+
+func TestLevel_String(t *testing.T) {
+	tests := map[string]struct {
+		l    output.Level
+		want string
+	}{
+		"fatal":   {l: output.Fatal, want: "fatal"},
+		"warning": {l: output.Warning, want: "warning"},
+		"trace":   {l: output.Trace, want: "trace"},
+		"invalid": {l: output.Trace + 1, want: "Level(7)"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.l.String(); got != tt.want {
+				t.Errorf("Level.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]struct {
+		s       string
+		want    output.Level
+		wantErr bool
+	}{
+		"fatal":      {s: "fatal", want: output.Fatal},
+		"mixed case": {s: " Debug ", want: output.Debug},
+		"warn":       {s: "WARN", want: output.Warning},
+		"unknown":    {s: "loud", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := output.ParseLevel(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
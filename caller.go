@@ -0,0 +1,90 @@
+package output
+
+import (
+	"maps"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// callerBus is a Bus that adds the location of the code that called Log to
+// each log message's fields
+type callerBus struct {
+	Bus
+	wrapperPackages map[string]bool
+}
+
+// The keys of the fields added by a Bus returned by NewCallerBus.
+const (
+	CallerFileKey     = "caller_file"
+	CallerLineKey     = "caller_line"
+	CallerFunctionKey = "caller_function"
+)
+
+var outputPackage = reflect.TypeFor[callerBus]().PkgPath()
+
+// NewCallerBus returns a Bus that passes every call through to b, except that
+// Log adds the file, line, and function of its caller to the log message's
+// fields (fields already present are not overwritten). Frames in this package,
+// and in the specified wrapper packages (identified by their import paths, e.g.,
+// "example.com/app/logging"), are skipped, so that the location reported is
+// that of the code that called the wrapper.
+func NewCallerBus(b Bus, wrapperPackages ...string) Bus {
+	cb := &callerBus{Bus: b, wrapperPackages: map[string]bool{outputPackage: true}}
+	for _, pkg := range wrapperPackages {
+		cb.wrapperPackages[pkg] = true
+	}
+	return cb
+}
+
+// Unwrap returns the Bus that the callerBus passes calls through to.
+func (cb *callerBus) Unwrap() Bus {
+	return cb.Bus
+}
+
+// Log adds the caller's location to the fields and logs the message.
+func (cb *callerBus) Log(l Level, msg string, fields map[string]any) {
+	if frame, ok := cb.caller(); ok {
+		withCaller := maps.Clone(fields)
+		if withCaller == nil {
+			withCaller = map[string]any{}
+		}
+		for k, v := range map[string]any{
+			CallerFileKey:     frame.File,
+			CallerLineKey:     frame.Line,
+			CallerFunctionKey: frame.Function,
+		} {
+			if _, exists := withCaller[k]; !exists {
+				withCaller[k] = v
+			}
+		}
+		fields = withCaller
+	}
+	cb.Bus.Log(l, msg, fields)
+}
+
+func (cb *callerBus) caller() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !cb.wrapperPackages[functionPackage(frame.Function)] {
+			return frame, frame.Function != ""
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// functionPackage returns the import path of the package containing the
+// function with the specified fully qualified name, e.g.,
+// "example.com/app.(*T).Method" is in package "example.com/app"
+func functionPackage(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[lastSlash+1:], "."); dot >= 0 {
+		return function[:lastSlash+1+dot]
+	}
+	return function
+}
@@ -0,0 +1,50 @@
+package output_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestNewCallerBus(t *testing.T) {
+	ml := output.NewMockLogger(t).Expect(output.Info, "hello", nil)
+	o := output.NewCallerBus(output.NewCustomBus(nil, nil, ml))
+	_, _, callLine, _ := runtime.Caller(0)
+	o.Log(output.Info, "hello", map[string]any{"k": "v"})
+	calls := ml.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("NewCallerBus() logged %d calls", len(calls))
+	}
+	fields := calls[0].Fields
+	if file, _ := fields[output.CallerFileKey].(string); !strings.HasSuffix(file, "caller_test.go") {
+		t.Errorf("NewCallerBus() file = %q", file)
+	}
+	if line, _ := fields[output.CallerLineKey].(int); line != callLine+1 {
+		t.Errorf("NewCallerBus() line = %d, want %d", line, callLine+1)
+	}
+	if function := fields[output.CallerFunctionKey]; function != "github.com/majohn-r/output_test.TestNewCallerBus" {
+		t.Errorf("NewCallerBus() function = %q", function)
+	}
+	if fields["k"] != "v" {
+		t.Errorf("NewCallerBus() lost field k: %v", fields)
+	}
+}
+
+func logThroughWrapper(o output.Bus) {
+	o.Log(output.Warning, "wrapped", map[string]any{output.CallerLineKey: -1})
+}
+
+func TestNewCallerBus_Wrapper(t *testing.T) {
+	r := output.NewRecorder()
+	o := output.NewCallerBus(r, "github.com/majohn-r/output_test")
+	logThroughWrapper(o)
+	if got := r.LogOutput(); !strings.Contains(got, "caller_function='testing.tRunner'") ||
+		!strings.Contains(got, "caller_line='-1'") {
+		t.Errorf("NewCallerBus() with wrapper logged %q", got)
+	}
+	if u, ok := o.(interface{ Unwrap() output.Bus }); !ok || u.Unwrap() != r {
+		t.Errorf("NewCallerBus() does not unwrap to the original Bus")
+	}
+}
@@ -0,0 +1,324 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+)
+
+type (
+	// CapturedCall is a single Bus call as serialized by a CapturingBus: one
+	// JSON object per line. Method is the name of the Bus function called;
+	// direct writes to the console and error writers are captured with the
+	// Method values "ConsoleWrite" and "ErrorWrite". Text is the text that the
+	// call produced, including tab and list decoration, and Result is the value
+	// returned by a query function such as Tab() or IsConsoleTTY().
+	//
+	// Arguments and field values are encoded the same way that JSONLogger
+	// encodes field values.
+	CapturedCall struct {
+		Method  string         `json:"method"`
+		Level   string         `json:"level,omitempty"`
+		Format  string         `json:"format,omitempty"`
+		Args    []any          `json:"args,omitempty"`
+		Message string         `json:"msg,omitempty"`
+		Fields  map[string]any `json:"fields,omitempty"`
+		Numeric bool           `json:"numeric,omitempty"`
+		Tab     uint8          `json:"tab,omitempty"`
+		Text    string         `json:"text,omitempty"`
+		Result  string         `json:"result,omitempty"`
+	}
+
+	// CapturingBus is an implementation of Bus that passes every call through
+	// to another Bus, while serializing the call, its arguments, and the text
+	// it produced to a writer, in the JSON lines format; the resulting file can
+	// be replayed against any Bus by calling Replay.
+	CapturingBus struct {
+		bus     Bus
+		lock    sync.Mutex
+		encoder *json.Encoder
+		err     error
+	}
+
+	capturingWriter struct {
+		cb     *CapturingBus
+		method string
+		writer io.Writer
+	}
+)
+
+// Method values used in a CapturedCall for writes made directly to the
+// writers returned by ConsoleWriter() and ErrorWriter().
+const (
+	ConsoleWriteMethod = "ConsoleWrite"
+	ErrorWriteMethod   = "ErrorWrite"
+)
+
+// NewCapturingBus returns a CapturingBus that passes calls through to b and
+// writes each call to w.
+func NewCapturingBus(b Bus, w io.Writer) *CapturingBus {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &CapturingBus{bus: b, encoder: encoder}
+}
+
+// Err returns the first error encountered writing captured calls, if any.
+func (cb *CapturingBus) Err() error {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	return cb.err
+}
+
+// Unwrap returns the Bus that the CapturingBus passes calls through to.
+func (cb *CapturingBus) Unwrap() Bus {
+	return cb.bus
+}
+
+func (cb *CapturingBus) capture(call CapturedCall) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	if err := cb.encoder.Encode(call); err != nil && cb.err == nil {
+		cb.err = err
+	}
+}
+
+func (cb *CapturingBus) consoleText(msg string) string {
+	return fmt.Sprintf("%*s%s%s", cb.bus.Tab(), "", cb.bus.ConsoleListDecorator().peek(), msg)
+}
+
+func (cb *CapturingBus) errorText(msg string) string {
+	return cb.bus.ErrorListDecorator().peek() + msg
+}
+
+// Log captures and logs a message and map of fields at a specified log level.
+func (cb *CapturingBus) Log(l Level, msg string, fields map[string]any) {
+	cb.capture(CapturedCall{Method: "Log", Level: l.String(), Message: msg, Fields: capturedFields(fields)})
+	cb.bus.Log(l, msg, fields)
+}
+
+// ConsolePrintf captures and prints a message with arguments to the console
+// channel
+func (cb *CapturingBus) ConsolePrintf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	cb.capture(CapturedCall{
+		Method:  "ConsolePrintf",
+		Format:  format,
+		Args:    capturedArgs(args),
+		Message: msg,
+		Text:    cb.consoleText(msg),
+	})
+	cb.bus.ConsolePrintf(format, args...)
+}
+
+// ConsolePrintln captures and prints a message to the console channel,
+// terminated by a newline
+func (cb *CapturingBus) ConsolePrintln(msg string) {
+	cb.capture(CapturedCall{Method: "ConsolePrintln", Message: msg, Text: cb.consoleText(msg + "\n")})
+	cb.bus.ConsolePrintln(msg)
+}
+
+// ErrorPrintf captures and prints a message with arguments to the error channel
+func (cb *CapturingBus) ErrorPrintf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	cb.capture(CapturedCall{
+		Method:  "ErrorPrintf",
+		Format:  format,
+		Args:    capturedArgs(args),
+		Message: msg,
+		Text:    cb.errorText(msg),
+	})
+	cb.bus.ErrorPrintf(format, args...)
+}
+
+// ErrorPrintln captures and prints a message to the error channel, terminated
+// by a newline
+func (cb *CapturingBus) ErrorPrintln(msg string) {
+	cb.capture(CapturedCall{Method: "ErrorPrintln", Message: msg, Text: cb.errorText(msg + "\n")})
+	cb.bus.ErrorPrintln(msg)
+}
+
+// ConsoleWriter returns a writer for console output; writes to it are captured.
+func (cb *CapturingBus) ConsoleWriter() io.Writer {
+	cb.capture(CapturedCall{Method: "ConsoleWriter"})
+	return &capturingWriter{cb: cb, method: ConsoleWriteMethod, writer: cb.bus.ConsoleWriter()}
+}
+
+// ErrorWriter returns a writer for error output; writes to it are captured.
+func (cb *CapturingBus) ErrorWriter() io.Writer {
+	cb.capture(CapturedCall{Method: "ErrorWriter"})
+	return &capturingWriter{cb: cb, method: ErrorWriteMethod, writer: cb.bus.ErrorWriter()}
+}
+
+// IsConsoleTTY captures and returns whether the console writer is a TTY
+func (cb *CapturingBus) IsConsoleTTY() bool {
+	b := cb.bus.IsConsoleTTY()
+	cb.capture(CapturedCall{Method: "IsConsoleTTY", Result: strconv.FormatBool(b)})
+	return b
+}
+
+// IsErrorTTY captures and returns whether the error writer is a TTY
+func (cb *CapturingBus) IsErrorTTY() bool {
+	b := cb.bus.IsErrorTTY()
+	cb.capture(CapturedCall{Method: "IsErrorTTY", Result: strconv.FormatBool(b)})
+	return b
+}
+
+// Tab captures and returns the current tab setting
+func (cb *CapturingBus) Tab() uint8 {
+	t := cb.bus.Tab()
+	cb.capture(CapturedCall{Method: "Tab", Result: strconv.Itoa(int(t))})
+	return t
+}
+
+// IncrementTab captures and increments the tab setting by the specified number
+// of spaces
+func (cb *CapturingBus) IncrementTab(t uint8) {
+	cb.capture(CapturedCall{Method: "IncrementTab", Tab: t})
+	cb.bus.IncrementTab(t)
+}
+
+// DecrementTab captures and decrements the tab setting by the specified number
+// of spaces
+func (cb *CapturingBus) DecrementTab(t uint8) {
+	cb.capture(CapturedCall{Method: "DecrementTab", Tab: t})
+	cb.bus.DecrementTab(t)
+}
+
+// BeginConsoleList captures and initiates console listing
+func (cb *CapturingBus) BeginConsoleList(numeric bool) {
+	cb.capture(CapturedCall{Method: "BeginConsoleList", Numeric: numeric})
+	cb.bus.BeginConsoleList(numeric)
+}
+
+// EndConsoleList captures and terminates console listing
+func (cb *CapturingBus) EndConsoleList() {
+	cb.capture(CapturedCall{Method: "EndConsoleList"})
+	cb.bus.EndConsoleList()
+}
+
+// ConsoleListDecorator captures and returns the console list decorator
+func (cb *CapturingBus) ConsoleListDecorator() *ListDecorator {
+	ld := cb.bus.ConsoleListDecorator()
+	cb.capture(CapturedCall{Method: "ConsoleListDecorator", Result: ld.peek()})
+	return ld
+}
+
+// BeginErrorList captures and initiates error listing
+func (cb *CapturingBus) BeginErrorList(numeric bool) {
+	cb.capture(CapturedCall{Method: "BeginErrorList", Numeric: numeric})
+	cb.bus.BeginErrorList(numeric)
+}
+
+// EndErrorList captures and terminates error listing
+func (cb *CapturingBus) EndErrorList() {
+	cb.capture(CapturedCall{Method: "EndErrorList"})
+	cb.bus.EndErrorList()
+}
+
+// ErrorListDecorator captures and returns the error list decorator
+func (cb *CapturingBus) ErrorListDecorator() *ListDecorator {
+	ld := cb.bus.ErrorListDecorator()
+	cb.capture(CapturedCall{Method: "ErrorListDecorator", Result: ld.peek()})
+	return ld
+}
+
+// Write captures the content and writes it to the underlying writer
+func (cw *capturingWriter) Write(p []byte) (int, error) {
+	cw.cb.capture(CapturedCall{Method: cw.method, Text: string(p)})
+	return cw.writer.Write(p)
+}
+
+func capturedArgs(args []any) []any {
+	if len(args) == 0 {
+		return nil
+	}
+	captured := make([]any, len(args))
+	for i, arg := range args {
+		captured[i] = encodableValue(arg)
+	}
+	return captured
+}
+
+func capturedFields(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return nil
+	}
+	captured := make(map[string]any, len(fields))
+	for k, v := range fields {
+		captured[k] = encodableValue(v)
+	}
+	return captured
+}
+
+// Replay reads calls captured by a CapturingBus from r and makes the same calls
+// on b. Printing calls are replayed with their formatted message, so b
+// generates its own tab and list decoration; query calls, such as Tab() and
+// IsConsoleTTY(), are skipped. Replay stops at the first line that cannot be
+// decoded or replayed and returns an error identifying the line.
+func Replay(r io.Reader, b Bus) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), math.MaxInt32)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var call CapturedCall
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&call); err != nil {
+			return fmt.Errorf("output: replay line %d: %w", lineNumber, err)
+		}
+		if err := replayCall(b, call); err != nil {
+			return fmt.Errorf("output: replay line %d: %w", lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func replayCall(b Bus, call CapturedCall) error {
+	switch call.Method {
+	case "Log":
+		l, err := ParseLevel(call.Level)
+		if err != nil {
+			return err
+		}
+		b.Log(l, call.Message, call.Fields)
+	case "ConsolePrintf":
+		b.ConsolePrintf("%s", call.Message)
+	case "ConsolePrintln":
+		b.ConsolePrintln(call.Message)
+	case "ErrorPrintf":
+		b.ErrorPrintf("%s", call.Message)
+	case "ErrorPrintln":
+		b.ErrorPrintln(call.Message)
+	case ConsoleWriteMethod:
+		_, _ = io.WriteString(b.ConsoleWriter(), call.Text)
+	case ErrorWriteMethod:
+		_, _ = io.WriteString(b.ErrorWriter(), call.Text)
+	case "IncrementTab":
+		b.IncrementTab(call.Tab)
+	case "DecrementTab":
+		b.DecrementTab(call.Tab)
+	case "BeginConsoleList":
+		b.BeginConsoleList(call.Numeric)
+	case "EndConsoleList":
+		b.EndConsoleList()
+	case "BeginErrorList":
+		b.BeginErrorList(call.Numeric)
+	case "EndErrorList":
+		b.EndErrorList()
+	case "ConsoleWriter", "ErrorWriter", "IsConsoleTTY", "IsErrorTTY", "Tab", "ConsoleListDecorator",
+		"ErrorListDecorator":
+		// queries have no side effects to replay
+	default:
+		return fmt.Errorf("unknown method %q", call.Method)
+	}
+	return nil
+}
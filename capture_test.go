@@ -0,0 +1,133 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/majohn-r/output"
+)
+
+func exerciseBus(o output.Bus) {
+	o.IncrementTab(2)
+	o.BeginConsoleList(true)
+	o.ConsolePrintf("%s has %d items\n", "list", 2)
+	o.ConsolePrintln("second")
+	o.EndConsoleList()
+	o.DecrementTab(2)
+	o.BeginErrorList(false)
+	o.ErrorPrintln("bad <thing>")
+	o.EndErrorList()
+	o.ErrorPrintf("error: %v\n", errors.New("oops"))
+	_, _ = fmt.Fprint(o.ConsoleWriter(), "raw\n")
+	o.Log(output.Warning, "careful", map[string]any{"count": 3, "err": errors.New("oops")})
+	_ = o.IsConsoleTTY()
+}
+
+func TestCapturingBus(t *testing.T) {
+	original := output.NewRecorder()
+	capture := &bytes.Buffer{}
+	cb := output.NewCapturingBus(original, capture)
+	exerciseBus(cb)
+	if err := cb.Err(); err != nil {
+		t.Errorf("CapturingBus.Err() = %v", err)
+	}
+	if got := cb.Unwrap(); got != original {
+		t.Errorf("CapturingBus.Unwrap() = %v, want %v", got, original)
+	}
+	wantRecording := output.WantedRecording{
+		Console: "   1. list has 2 items\n   2. second\nraw\n",
+		Error:   "● bad <thing>\nerror: oops\n",
+		Log:     "level='warning' count='3' err='oops' msg='careful'\n",
+	}
+	original.Report(t, "CapturingBus", wantRecording)
+	wantLines := []string{
+		`{"method":"IncrementTab","tab":2}`,
+		`{"method":"BeginConsoleList","numeric":true}`,
+		`{"method":"ConsolePrintf","format":"%s has %d items\n","args":["list",2],"msg":"list has 2 items\n","text":"   1. list has 2 items\n"}`,
+		`{"method":"ConsolePrintln","msg":"second","text":"   2. second\n"}`,
+		`{"method":"EndConsoleList"}`,
+		`{"method":"DecrementTab","tab":2}`,
+		`{"method":"BeginErrorList"}`,
+		`{"method":"ErrorPrintln","msg":"bad <thing>","text":"● bad <thing>\n"}`,
+		`{"method":"EndErrorList"}`,
+		`{"method":"ErrorPrintf","format":"error: %v\n","args":["oops"],"msg":"error: oops\n","text":"error: oops\n"}`,
+		`{"method":"ConsoleWriter"}`,
+		`{"method":"ConsoleWrite","text":"raw\n"}`,
+		`{"method":"Log","level":"warning","msg":"careful","fields":{"count":3,"err":"oops"}}`,
+		`{"method":"IsConsoleTTY","result":"false"}`,
+	}
+	gotLines := strings.Split(strings.TrimSuffix(capture.String(), "\n"), "\n")
+	if len(gotLines) != len(wantLines) {
+		t.Fatalf("CapturingBus captured %d lines, want %d:\n%s", len(gotLines), len(wantLines), capture.String())
+	}
+	for i, line := range gotLines {
+		if line != wantLines[i] {
+			t.Errorf("CapturingBus line %d = %s, want %s", i+1, line, wantLines[i])
+		}
+	}
+	replayed := output.NewRecorder()
+	if err := output.Replay(strings.NewReader(capture.String()), replayed); err != nil {
+		t.Errorf("Replay() error = %v", err)
+	}
+	replayed.Report(t, "Replay", wantRecording)
+}
+
+func TestReplay_JSONLogger(t *testing.T) {
+	clock := func() time.Time { return time.Date(2026, 10, 18, 9, 15, 2, 0, time.UTC) }
+	fields := map[string]any{"count": 3, "ratio": 0.5, "big": int64(1) << 60, "name": "x"}
+	direct := &bytes.Buffer{}
+	output.NewBus(output.WithLogger(output.NewJSONLogger(direct).WithClock(clock))).Log(output.Info, "counted", fields)
+	captured := &bytes.Buffer{}
+	output.NewCapturingBus(output.NewNilBus(), captured).Log(output.Info, "counted", fields)
+	replayed := &bytes.Buffer{}
+	err := output.Replay(captured, output.NewBus(output.WithLogger(output.NewJSONLogger(replayed).WithClock(clock))))
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if got, want := replayed.String(), direct.String(); got != want {
+		t.Errorf("Replay() logged %s, want %s", got, want)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		wantErr string
+		output.WantedRecording
+	}{
+		"empty lines": {
+			input:           "\n{\"method\":\"ErrorPrintln\",\"msg\":\"x\"}\n\n",
+			WantedRecording: output.WantedRecording{Error: "x\n"},
+		},
+		"direct error write": {
+			input:           `{"method":"ErrorWrite","text":"raw"}`,
+			WantedRecording: output.WantedRecording{Error: "raw"},
+		},
+		"bad json": {
+			input:   "{\"method\":\"Tab\"}\n{",
+			wantErr: "output: replay line 2: unexpected EOF",
+		},
+		"bad level": {
+			input:   `{"method":"Log","level":"loud"}`,
+			wantErr: `output: replay line 1: output: unknown log level "loud"`,
+		},
+		"bad method": {
+			input:   `{"method":"Shout"}`,
+			wantErr: `output: replay line 1: unknown method "Shout"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := output.NewRecorder()
+			err := output.Replay(strings.NewReader(tt.input), o)
+			if got := fmt.Sprint(err); (err != nil || tt.wantErr != "") && got != tt.wantErr {
+				t.Errorf("Replay() error = %q, want %q", got, tt.wantErr)
+			}
+			o.Report(t, "Replay()", tt.WantedRecording)
+		})
+	}
+}
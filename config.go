@@ -0,0 +1,240 @@
+package output
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Config describes how to build a Bus: where console output goes, and
+	// which Logger to use, at what level, writing where. Populate it with
+	// DefaultConfig, ConfigFromEnv, and RegisterFlags, and then call NewBus.
+	Config struct {
+		// LogLevel is the least severe level that is logged
+		LogLevel Level
+		// LogFormat selects the Logger: LogFormatJSON, LogFormatLogfmt, or
+		// LogFormatNil
+		LogFormat string
+		// LogFile is where log messages are written: LogFileStderr,
+		// LogFileStdout, or the path of a file, which is appended to
+		LogFile string
+		// NoColor disables colored output; see ColorEnabled
+		NoColor bool
+		// Quiet discards console output; error output is still written
+		Quiet bool
+		// Verbose makes LogLevel that many levels more verbose, up to Trace
+		Verbose int
+		// Console and Errors, if not nil, replace os.Stdout and os.Stderr as
+		// the console and error writers; they are not affected by the
+		// environment or by flags
+		Console io.Writer
+		Errors  io.Writer
+	}
+
+	levelFlag struct {
+		level *Level
+	}
+
+	closerFunc func() error
+)
+
+// These are the LogFormat values that Config accepts.
+const (
+	LogFormatJSON   = "json"
+	LogFormatLogfmt = "logfmt"
+	LogFormatNil    = "nil"
+)
+
+// These are the LogFile values that Config treats as standard streams, rather
+// than as file paths.
+const (
+	LogFileStderr = "stderr"
+	LogFileStdout = "stdout"
+)
+
+// DefaultConfig returns a Config that logs info and more severe log messages,
+// as JSON, to stderr.
+func DefaultConfig() Config {
+	return Config{LogLevel: Info, LogFormat: LogFormatJSON, LogFile: LogFileStderr}
+}
+
+// ConfigFromEnv returns DefaultConfig, modified by the environment variables
+// named with the specified prefix, e.g., for the prefix "APP": APP_LOG_LEVEL
+// (a level name, as accepted by ParseLevel), APP_LOG_FORMAT, APP_LOG_FILE,
+// APP_NO_COLOR, APP_QUIET (a boolean, as accepted by strconv.ParseBool), and
+// APP_VERBOSE (an integer). Following https://no-color.org, a non-empty NO_COLOR
+// variable also sets NoColor. Variables that are not set are ignored; values
+// that cannot be parsed are reported together in the returned error, and leave
+// the corresponding settings unchanged.
+func ConfigFromEnv(prefix string) (Config, error) {
+	c := DefaultConfig()
+	var errs []error
+	env := func(name string) (string, bool) {
+		return os.LookupEnv(prefix + "_" + name)
+	}
+	if value, ok := env("LOG_LEVEL"); ok {
+		if l, err := ParseLevel(value); err != nil {
+			errs = append(errs, err)
+		} else {
+			c.LogLevel = l
+		}
+	}
+	if value, ok := env("LOG_FORMAT"); ok {
+		if err := validateLogFormat(value); err != nil {
+			errs = append(errs, err)
+		} else {
+			c.LogFormat = value
+		}
+	}
+	if value, ok := env("LOG_FILE"); ok {
+		c.LogFile = value
+	}
+	if value := os.Getenv("NO_COLOR"); value != "" {
+		c.NoColor = true
+	}
+	for _, setting := range []struct {
+		name  string
+		value *bool
+	}{{name: "NO_COLOR", value: &c.NoColor}, {name: "QUIET", value: &c.Quiet}} {
+		name := setting.name
+		if value, ok := env(name); ok {
+			if b, err := strconv.ParseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("output: invalid %s_%s value %q", prefix, name, value))
+			} else {
+				*setting.value = b
+			}
+		}
+	}
+	if value, ok := env("VERBOSE"); ok {
+		if n, err := strconv.Atoi(value); err != nil {
+			errs = append(errs, fmt.Errorf("output: invalid %s_VERBOSE value %q", prefix, value))
+		} else {
+			c.Verbose = n
+		}
+	}
+	return c, errors.Join(errs...)
+}
+
+// RegisterFlags registers flags on fs that set the Config's fields: -log-level,
+// -log-format, -log-file, -no-color, -quiet, and -verbose. The Config's current
+// values are the flags' defaults, so populate the Config from the environment
+// first if the flags should override the environment.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(levelFlag{level: &c.LogLevel}, "log-level",
+		"least severe `level` to log: trace, debug, info, warning, error, panic, or fatal")
+	fs.Func("log-format", fmt.Sprintf("log `format`: %s, %s, or %s (default %q)",
+		LogFormatJSON, LogFormatLogfmt, LogFormatNil, c.LogFormat), func(s string) error {
+		if err := validateLogFormat(s); err != nil {
+			return err
+		}
+		c.LogFormat = s
+		return nil
+	})
+	fs.StringVar(&c.LogFile, "log-file", c.LogFile,
+		fmt.Sprintf("log destination: %s, %s, or a file `path`", LogFileStderr, LogFileStdout))
+	fs.BoolVar(&c.NoColor, "no-color", c.NoColor, "disable colored output")
+	fs.BoolVar(&c.Quiet, "quiet", c.Quiet, "discard console output")
+	fs.IntVar(&c.Verbose, "verbose", c.Verbose, "make the log level this many levels more verbose")
+}
+
+func (lf levelFlag) String() string {
+	if lf.level == nil {
+		return ""
+	}
+	return lf.level.String()
+}
+
+func (lf levelFlag) Set(s string) error {
+	l, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*lf.level = l
+	return nil
+}
+
+func validateLogFormat(format string) error {
+	switch format {
+	case LogFormatJSON, LogFormatLogfmt, LogFormatNil:
+		return nil
+	default:
+		return fmt.Errorf("output: unknown log format %q", format)
+	}
+}
+
+// EffectiveLogLevel returns LogLevel, made more verbose by Verbose, but no
+// more verbose than Trace.
+func (c Config) EffectiveLogLevel() Level {
+	l := int(c.LogLevel) + max(c.Verbose, 0)
+	return Level(min(l, int(Trace)))
+}
+
+// ColorEnabled returns whether code writing to b's console should use color:
+// NoColor must not be set, and the console writer must be a TTY.
+func (c Config) ColorEnabled(b Bus) bool {
+	return !c.NoColor && b.IsConsoleTTY()
+}
+
+// NewBus returns a Bus built according to the Config, and an io.Closer that
+// closes the log file, if one was opened; the caller should close it when the
+// Bus is no longer needed. Log messages less severe than the effective log
+// level (see EffectiveLogLevel) are discarded.
+func (c Config) NewBus() (Bus, io.Closer, error) {
+	if err := validateLogFormat(c.LogFormat); err != nil {
+		return nil, nil, err
+	}
+	console := c.Console
+	if console == nil {
+		console = os.Stdout
+	}
+	if c.Quiet {
+		console = NilWriter{}
+	}
+	errorWriter := c.Errors
+	if errorWriter == nil {
+		errorWriter = os.Stderr
+	}
+	opts := []Option{WithConsoleWriter(console), WithErrorWriter(errorWriter)}
+	var closer io.Closer = noopCloser
+	if c.LogFormat != LogFormatNil {
+		w, fileCloser, err := c.openLogFile(errorWriter)
+		if err != nil {
+			return nil, nil, err
+		}
+		closer = fileCloser
+		var logger Logger = NewJSONLogger(w)
+		if c.LogFormat == LogFormatLogfmt {
+			logger = NewLogfmtLogger(w)
+		}
+		opts = append(opts, WithLogger(logger), WithLogLevel(c.EffectiveLogLevel()))
+	}
+	return NewBus(opts...), closer, nil
+}
+
+func (c Config) openLogFile(errorWriter io.Writer) (io.Writer, io.Closer, error) {
+	switch strings.ToLower(c.LogFile) {
+	case "", LogFileStderr:
+		return errorWriter, noopCloser, nil
+	case LogFileStdout:
+		if c.Console != nil {
+			return c.Console, noopCloser, nil
+		}
+		return os.Stdout, noopCloser, nil
+	}
+	file, err := os.OpenFile(c.LogFile, logFileFlags, logFilePermissions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file, nil
+}
+
+var noopCloser io.Closer = closerFunc(func() error { return nil })
+
+func (cf closerFunc) Close() error {
+	return cf()
+}
@@ -0,0 +1,215 @@
+package output_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := map[string]struct {
+		env     map[string]string
+		want    output.Config
+		wantErr string
+	}{
+		"defaults": {want: output.DefaultConfig()},
+		"everything": {
+			env: map[string]string{
+				"APP_LOG_LEVEL":  "WARN",
+				"APP_LOG_FORMAT": "logfmt",
+				"APP_LOG_FILE":   "/var/log/app.log",
+				"APP_QUIET":      "true",
+				"APP_VERBOSE":    "2",
+				"NO_COLOR":       "1",
+			},
+			want: output.Config{
+				LogLevel:  output.Warning,
+				LogFormat: output.LogFormatLogfmt,
+				LogFile:   "/var/log/app.log",
+				NoColor:   true,
+				Quiet:     true,
+				Verbose:   2,
+			},
+		},
+		"prefixed NO_COLOR overrides NO_COLOR": {
+			env:  map[string]string{"NO_COLOR": "1", "APP_NO_COLOR": "false"},
+			want: output.DefaultConfig(),
+		},
+		"bad values": {
+			env: map[string]string{
+				"APP_LOG_LEVEL":  "loud",
+				"APP_LOG_FORMAT": "xml",
+				"APP_QUIET":      "sometimes",
+				"APP_VERBOSE":    "very",
+			},
+			want: output.DefaultConfig(),
+			wantErr: "output: unknown log level \"loud\"\n" +
+				"output: unknown log format \"xml\"\n" +
+				"output: invalid APP_QUIET value \"sometimes\"\n" +
+				"output: invalid APP_VERBOSE value \"very\"",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, variable := range []string{"NO_COLOR", "APP_LOG_LEVEL", "APP_LOG_FORMAT", "APP_LOG_FILE",
+				"APP_NO_COLOR", "APP_QUIET", "APP_VERBOSE"} {
+				t.Setenv(variable, "")
+				_ = os.Unsetenv(variable)
+			}
+			for variable, value := range tt.env {
+				t.Setenv(variable, value)
+			}
+			got, err := output.ConfigFromEnv("APP")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConfigFromEnv() = %+v, want %+v", got, tt.want)
+			}
+			if gotErr := errorText(err); gotErr != tt.wantErr {
+				t.Errorf("ConfigFromEnv() error = %q, want %q", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	c := output.DefaultConfig()
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c.RegisterFlags(fs)
+	err := fs.Parse([]string{"-log-level", "debug", "-log-format", "nil", "-log-file", "stdout", "-no-color",
+		"-quiet", "-verbose", "1"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := output.Config{
+		LogLevel:  output.Debug,
+		LogFormat: output.LogFormatNil,
+		LogFile:   output.LogFileStdout,
+		NoColor:   true,
+		Quiet:     true,
+		Verbose:   1,
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("RegisterFlags() produced %+v, want %+v", c, want)
+	}
+	for _, args := range [][]string{{"-log-level", "loud"}, {"-log-format", "xml"}} {
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("Parse(%v) succeeded", args)
+		}
+	}
+	usage := &bytes.Buffer{}
+	fs.SetOutput(usage)
+	fs.PrintDefaults()
+	if !strings.Contains(usage.String(), "-log-level level") {
+		t.Errorf("PrintDefaults() = %q", usage.String())
+	}
+}
+
+func TestConfig_EffectiveLogLevel(t *testing.T) {
+	tests := map[string]struct {
+		c    output.Config
+		want output.Level
+	}{
+		"as is":        {c: output.Config{LogLevel: output.Warning}, want: output.Warning},
+		"more verbose": {c: output.Config{LogLevel: output.Warning, Verbose: 2}, want: output.Debug},
+		"capped":       {c: output.Config{LogLevel: output.Info, Verbose: 10}, want: output.Trace},
+		"negative":     {c: output.Config{LogLevel: output.Info, Verbose: -1}, want: output.Info},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.c.EffectiveLogLevel(); got != tt.want {
+				t.Errorf("EffectiveLogLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_NewBus(t *testing.T) {
+	console := &bytes.Buffer{}
+	errorOutput := &bytes.Buffer{}
+	c := output.Config{LogLevel: output.Warning, LogFormat: output.LogFormatLogfmt, Console: console,
+		Errors: errorOutput}
+	o, closer, err := c.NewBus()
+	if err != nil {
+		t.Fatalf("NewBus() error = %v", err)
+	}
+	o.ConsolePrintln("hello")
+	o.Log(output.Info, "discarded", nil)
+	o.Log(output.Warning, "kept", nil)
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if got := console.String(); got != "hello\n" {
+		t.Errorf("NewBus() console output = %q", got)
+	}
+	if got := errorOutput.String(); strings.Contains(got, "discarded") || !strings.Contains(got, "msg=kept") {
+		t.Errorf("NewBus() log output = %q", got)
+	}
+	if c.ColorEnabled(o) {
+		t.Errorf("ColorEnabled() = true for a non-TTY console")
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	c = output.Config{LogLevel: output.Trace, LogFormat: output.LogFormatJSON, LogFile: path, Quiet: true,
+		Console: console, Errors: errorOutput}
+	o, closer, err = c.NewBus()
+	if err != nil {
+		t.Fatalf("NewBus() error = %v", err)
+	}
+	o.ConsolePrintln("discarded")
+	o.Log(output.Trace, "traced", nil)
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if got := console.String(); got != "hello\n" {
+		t.Errorf("NewBus() quiet console output = %q", got)
+	}
+	if content, _ := os.ReadFile(path); !strings.Contains(string(content), `"msg":"traced"`) {
+		t.Errorf("NewBus() log file content = %q", content)
+	}
+
+	for name, c := range map[string]output.Config{
+		"bad format": {LogFormat: "xml"},
+		"bad file":   {LogFormat: output.LogFormatJSON, LogFile: filepath.Join(t.TempDir(), "missing", "app.log")},
+	} {
+		if _, _, err := c.NewBus(); err == nil {
+			t.Errorf("NewBus() %s succeeded", name)
+		}
+	}
+}
+
+func TestConfig_NewBus_nilLogger(t *testing.T) {
+	console := &bytes.Buffer{}
+	c := output.Config{LogLevel: output.Info, LogFormat: output.LogFormatNil, LogFile: output.LogFileStdout,
+		Console: console}
+	o, closer, err := c.NewBus()
+	if err != nil {
+		t.Fatalf("NewBus() error = %v", err)
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+	o.Log(output.Error, "discarded", nil)
+	if console.Len() != 0 {
+		t.Errorf("NewBus() with the nil logger wrote %q", console.String())
+	}
+	c.LogFormat = output.LogFormatJSON
+	o, _, _ = c.NewBus()
+	o.Log(output.Error, "logged", nil)
+	if !strings.Contains(console.String(), `"msg":"logged"`) {
+		t.Errorf("NewBus() logging to stdout wrote %q", console.String())
+	}
+}
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"context"
+	"maps"
+)
+
+type (
+	busContextKey    struct{}
+	fieldsContextKey struct{}
+
+	// contextBus is a Bus that merges fields attached to a context into the
+	// fields of each log message
+	contextBus struct {
+		Bus
+		fields map[string]any
+	}
+)
+
+// NewContext returns a copy of ctx that carries b; library code that receives
+// only the context can retrieve b with FromContext.
+func NewContext(ctx context.Context, b Bus) context.Context {
+	return context.WithValue(ctx, busContextKey{}, b)
+}
+
+// FromContext returns the Bus carried by ctx or, if ctx carries no Bus, a Bus
+// that does nothing, as returned by NewNilBus. If fields have been attached to
+// ctx by ContextWithFields, the returned Bus's Log function merges them into
+// each log message's fields; fields passed to Log take precedence.
+func FromContext(ctx context.Context) Bus {
+	b, ok := ctx.Value(busContextKey{}).(Bus)
+	if !ok {
+		b = NewNilBus()
+	}
+	if fields := contextFields(ctx); len(fields) > 0 {
+		return &contextBus{Bus: b, fields: fields}
+	}
+	return b
+}
+
+// ContextWithFields returns a copy of ctx with the specified log fields
+// attached, in addition to any fields already attached; where keys collide,
+// the specified fields take precedence.
+func ContextWithFields(ctx context.Context, fields map[string]any) context.Context {
+	merged := maps.Clone(contextFields(ctx))
+	if merged == nil {
+		merged = make(map[string]any, len(fields))
+	}
+	maps.Copy(merged, fields)
+	return context.WithValue(ctx, fieldsContextKey{}, merged)
+}
+
+// FieldsFromContext returns a copy of the log fields attached to ctx.
+func FieldsFromContext(ctx context.Context) map[string]any {
+	return maps.Clone(contextFields(ctx))
+}
+
+// LogContext logs a message and map of fields at a specified log level on the
+// Bus returned by FromContext(ctx), merging in the log fields attached to ctx.
+func LogContext(ctx context.Context, l Level, msg string, fields map[string]any) {
+	FromContext(ctx).Log(l, msg, fields)
+}
+
+func contextFields(ctx context.Context) map[string]any {
+	fields, _ := ctx.Value(fieldsContextKey{}).(map[string]any)
+	return fields
+}
+
+// Unwrap returns the Bus that the contextBus passes calls through to.
+func (cb *contextBus) Unwrap() Bus {
+	return cb.Bus
+}
+
+// Log merges the context's fields into the specified fields and logs the
+// message.
+func (cb *contextBus) Log(l Level, msg string, fields map[string]any) {
+	merged := maps.Clone(cb.fields)
+	maps.Copy(merged, fields)
+	cb.Bus.Log(l, msg, merged)
+}
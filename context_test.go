@@ -0,0 +1,47 @@
+package output_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestFromContext(t *testing.T) {
+	o := output.NewRecorder()
+	ctx := output.NewContext(context.Background(), o)
+	if got := output.FromContext(ctx); got != o {
+		t.Errorf("FromContext() = %v, want %v", got, o)
+	}
+	if got := output.FromContext(context.Background()); got == nil {
+		t.Errorf("FromContext() without a Bus returned nil")
+	} else {
+		got.ConsolePrintln("goes nowhere")
+		got.Log(output.Info, "goes nowhere", nil)
+	}
+	ctx = output.ContextWithFields(ctx, map[string]any{"request_id": "r1", "trace_id": "t1"})
+	ctx = output.ContextWithFields(ctx, map[string]any{"trace_id": "t2"})
+	output.LogContext(ctx, output.Info, "handled", map[string]any{"status": 200})
+	output.FromContext(ctx).Log(output.Warning, "override", map[string]any{"request_id": "explicit"})
+	output.FromContext(ctx).ConsolePrintln("console is unaffected")
+	o.Report(t, "LogContext()", output.WantedRecording{
+		Console: "console is unaffected\n",
+		Log: "level='info' request_id='r1' status='200' trace_id='t2' msg='handled'\n" +
+			"level='warning' request_id='explicit' trace_id='t2' msg='override'\n",
+	})
+	if u, ok := output.FromContext(ctx).(interface{ Unwrap() output.Bus }); !ok || u.Unwrap() != o {
+		t.Errorf("FromContext() with fields does not unwrap to the original Bus")
+	}
+}
+
+func TestFieldsFromContext(t *testing.T) {
+	if got := output.FieldsFromContext(context.Background()); got != nil {
+		t.Errorf("FieldsFromContext() = %v, want nil", got)
+	}
+	ctx := output.ContextWithFields(context.Background(), map[string]any{"a": 1})
+	fields := output.FieldsFromContext(ctx)
+	fields["b"] = 2
+	if got := output.FieldsFromContext(ctx); len(got) != 1 || got["a"] != 1 {
+		t.Errorf("FieldsFromContext() = %v, want map[a:1]", got)
+	}
+}
@@ -0,0 +1,160 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+type (
+	// StackTrace is a call stack, as captured by WithStack.
+	StackTrace []uintptr
+
+	// ErrorChain is the list of error messages that ErrorFields records under
+	// ErrorChainKey; JSONLogger encodes it as an array of strings.
+	ErrorChain []string
+
+	stackError struct {
+		err   error
+		stack StackTrace
+	}
+)
+
+// The keys of the fields added by ErrorFields.
+const (
+	ErrorKey      = "error"
+	ErrorChainKey = "error_chain"
+	ErrorStackKey = "error_stack"
+)
+
+// WithStack returns an error that wraps err and carries the call stack of its
+// caller; it returns nil if err is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	return &stackError{err: err, stack: pcs[:n]}
+}
+
+func (se *stackError) Error() string {
+	return se.err.Error()
+}
+
+func (se *stackError) Unwrap() error {
+	return se.err
+}
+
+// StackTrace returns the call stack captured by WithStack.
+func (se *stackError) StackTrace() StackTrace {
+	return se.stack
+}
+
+// String formats the stack trace with one function per line, each followed by
+// its file and line number on a separate, indented line.
+func (st StackTrace) String() string {
+	builder := &strings.Builder{}
+	frames := runtime.CallersFrames(st)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
+
+// MarshalJSON encodes the chain as an array of strings.
+func (ec ErrorChain) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string(ec))
+}
+
+// ErrorFields returns log fields describing err: its message; if err wraps
+// other errors (including the branches of errors created by errors.Join), the
+// messages of every error in the chain, in depth-first order; and, if any error
+// in the chain carries a stack trace, the innermost stack trace. An error
+// carries a stack trace if it has a StackTrace function with no parameters
+// and one result, as errors created by WithStack and by
+// github.com/pkg/errors do. ErrorFields returns nil if err is nil.
+func ErrorFields(err error) map[string]any {
+	if err == nil {
+		return nil
+	}
+	fields := map[string]any{ErrorKey: err.Error()}
+	var chain ErrorChain
+	var stack string
+	walkErrors(err, func(e error) {
+		chain = append(chain, e.Error())
+		if s, ok := stackTraceOf(e); ok {
+			stack = s
+		}
+	})
+	if len(chain) > 1 {
+		fields[ErrorChainKey] = chain
+	}
+	if stack != "" {
+		fields[ErrorStackKey] = stack
+	}
+	return fields
+}
+
+// walkErrors calls visit for err and for every error it wraps, depth-first
+func walkErrors(err error, visit func(error)) {
+	if err == nil {
+		return
+	}
+	visit(err)
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		walkErrors(wrapper.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, e := range wrapper.Unwrap() {
+			walkErrors(e, visit)
+		}
+	}
+}
+
+// stackTraceOf returns the formatted stack trace carried by err itself (not by
+// the errors it wraps)
+func stackTraceOf(err error) (string, bool) {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return "", false
+	}
+	return fmt.Sprintf("%+v", method.Call(nil)[0].Interface()), true
+}
+
+// LogError logs msg at the specified level, with fields describing err (as
+// returned by ErrorFields) merged into the specified fields; the specified
+// fields take precedence.
+func LogError(b Bus, l Level, msg string, err error, fields map[string]any) {
+	merged := ErrorFields(err)
+	if merged == nil {
+		b.Log(l, msg, fields)
+		return
+	}
+	maps.Copy(merged, fields)
+	b.Log(l, msg, merged)
+}
+
+// ReportError prints a user-friendly description of err, prefaced by msg (if
+// msg is not empty), on b's error channel, and logs the full details of err at
+// the Error level, as LogError does.
+func ReportError(b Bus, msg string, err error, fields map[string]any) {
+	switch {
+	case err == nil:
+		b.ErrorPrintln(msg)
+	case msg == "":
+		b.ErrorPrintln(err.Error())
+	default:
+		b.ErrorPrintln(fmt.Sprintf("%s: %v", msg, err))
+	}
+	LogError(b, Error, msg, err, fields)
+}
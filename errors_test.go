@@ -0,0 +1,148 @@
+package output_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+type foreignStackTrace struct{}
+
+func (foreignStackTrace) Format(s fmt.State, verb rune) {
+	if s.Flag('+') && verb == 'v' {
+		_, _ = fmt.Fprint(s, "foreign stack, in detail")
+		return
+	}
+	_, _ = fmt.Fprint(s, "foreign stack")
+}
+
+// foreignError carries a stack trace the way github.com/pkg/errors does
+type foreignError struct{ msg string }
+
+func (fe foreignError) Error() string                     { return fe.msg }
+func (fe foreignError) StackTrace() foreignStackTrace     { return foreignStackTrace{} }
+func (fe foreignError) Unrelated(_ int) foreignStackTrace { return foreignStackTrace{} }
+
+func TestErrorFields(t *testing.T) {
+	base := errors.New("permission denied")
+	wrapped := fmt.Errorf("open config: %w", base)
+	joined := errors.Join(wrapped, errors.New("disk full"))
+	tests := map[string]struct {
+		err  error
+		want map[string]any
+	}{
+		"nil": {err: nil, want: nil},
+		"simple": {
+			err:  base,
+			want: map[string]any{output.ErrorKey: "permission denied"},
+		},
+		"wrapped": {
+			err: wrapped,
+			want: map[string]any{
+				output.ErrorKey:      "open config: permission denied",
+				output.ErrorChainKey: output.ErrorChain{"open config: permission denied", "permission denied"},
+			},
+		},
+		"joined": {
+			err: joined,
+			want: map[string]any{
+				output.ErrorKey: "open config: permission denied\ndisk full",
+				output.ErrorChainKey: output.ErrorChain{
+					"open config: permission denied\ndisk full",
+					"open config: permission denied",
+					"permission denied",
+					"disk full",
+				},
+			},
+		},
+		"foreign stack": {
+			err: fmt.Errorf("load: %w", foreignError{msg: "bad"}),
+			want: map[string]any{
+				output.ErrorKey:      "load: bad",
+				output.ErrorChainKey: output.ErrorChain{"load: bad", "bad"},
+				output.ErrorStackKey: "foreign stack, in detail",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := output.ErrorFields(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ErrorFields() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	if got := output.WithStack(nil); got != nil {
+		t.Errorf("WithStack(nil) = %v, want nil", got)
+	}
+	base := errors.New("boom")
+	err := fmt.Errorf("outer: %w", output.WithStack(base))
+	if !errors.Is(err, base) {
+		t.Errorf("WithStack() does not wrap its error")
+	}
+	if got := err.Error(); got != "outer: boom" {
+		t.Errorf("Error() = %q, want %q", got, "outer: boom")
+	}
+	stack, _ := output.ErrorFields(err)[output.ErrorStackKey].(string)
+	if !strings.Contains(stack, "output_test.TestWithStack\n\t") || !strings.Contains(stack, "errors_test.go:") {
+		t.Errorf("ErrorFields() stack = %q, want it to identify TestWithStack", stack)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	err := fmt.Errorf("open config: %w", errors.New("permission denied"))
+	tests := map[string]struct {
+		msg    string
+		err    error
+		fields map[string]any
+		output.WantedRecording
+	}{
+		"nil error": {
+			msg: "nothing went wrong",
+			WantedRecording: output.WantedRecording{
+				Error: "nothing went wrong\n",
+				Log:   "level='error'  msg='nothing went wrong'\n",
+			},
+		},
+		"no message": {
+			err: err,
+			WantedRecording: output.WantedRecording{
+				Error: "open config: permission denied\n",
+				Log: "level='error' error='open config: permission denied'" +
+					" error_chain='[open config: permission denied permission denied]' msg=''\n",
+			},
+		},
+		"message and fields": {
+			msg:    "cannot start",
+			err:    err,
+			fields: map[string]any{"error": "overridden", "file": "app.yaml"},
+			WantedRecording: output.WantedRecording{
+				Error: "cannot start: open config: permission denied\n",
+				Log: "level='error' error='overridden'" +
+					" error_chain='[open config: permission denied permission denied]'" +
+					" file='app.yaml' msg='cannot start'\n",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := output.NewRecorder()
+			output.ReportError(o, tt.msg, tt.err, tt.fields)
+			o.Report(t, "ReportError()", tt.WantedRecording)
+		})
+	}
+}
+
+func TestLogError(t *testing.T) {
+	o := output.NewRecorder()
+	output.LogError(o, output.Warning, "retrying", errors.New("timeout"), map[string]any{"attempt": 2})
+	o.Report(t, "LogError()", output.WantedRecording{
+		Log: "level='warning' attempt='2' error='timeout' msg='retrying'\n",
+	})
+}
@@ -0,0 +1,97 @@
+package output
+
+type (
+	// Route directs log messages to a Logger; see FanOutLogger.
+	Route struct {
+		// Logger receives the log messages that pass the route's tests
+		Logger Logger
+		// MinLevel is the least severe level that the route accepts; for
+		// example, Warning accepts warning, error, panic, and fatal log
+		// messages, and Trace accepts everything
+		MinLevel Level
+		// Filter, if not nil, must return true for a log message's fields for
+		// the route to accept the log message
+		Filter func(fields map[string]any) bool
+	}
+
+	// FanOutLogger is a Logger that passes each log message to every Route
+	// that accepts it, in the order in which the Routes were specified.
+	//
+	// A panic in one Route's Logger (or Filter) does not stop the log message
+	// from being passed to the remaining Routes; once every Route has been
+	// tried, the first panic is propagated if the log message is a panic log
+	// message, and is discarded otherwise. A Logger that exits the program on
+	// a fatal log message does stop the remaining Routes, so such Loggers
+	// should be specified last.
+	FanOutLogger struct {
+		routes []Route
+	}
+)
+
+// NewFanOutLogger returns a FanOutLogger that passes log messages to the
+// specified Routes.
+func NewFanOutLogger(routes ...Route) *FanOutLogger {
+	return &FanOutLogger{routes: routes}
+}
+
+// Trace passes a trace log message to the Routes that accept it.
+func (fol *FanOutLogger) Trace(msg string, fields map[string]any) {
+	fol.dispatch(Trace, msg, fields)
+}
+
+// Debug passes a debug log message to the Routes that accept it.
+func (fol *FanOutLogger) Debug(msg string, fields map[string]any) {
+	fol.dispatch(Debug, msg, fields)
+}
+
+// Info passes an info log message to the Routes that accept it.
+func (fol *FanOutLogger) Info(msg string, fields map[string]any) {
+	fol.dispatch(Info, msg, fields)
+}
+
+// Warning passes a warning log message to the Routes that accept it.
+func (fol *FanOutLogger) Warning(msg string, fields map[string]any) {
+	fol.dispatch(Warning, msg, fields)
+}
+
+// Error passes an error log message to the Routes that accept it.
+func (fol *FanOutLogger) Error(msg string, fields map[string]any) {
+	fol.dispatch(Error, msg, fields)
+}
+
+// Panic passes a panic log message to the Routes that accept it.
+func (fol *FanOutLogger) Panic(msg string, fields map[string]any) {
+	fol.dispatch(Panic, msg, fields)
+}
+
+// Fatal passes a fatal log message to the Routes that accept it.
+func (fol *FanOutLogger) Fatal(msg string, fields map[string]any) {
+	fol.dispatch(Fatal, msg, fields)
+}
+
+func (fol *FanOutLogger) dispatch(l Level, msg string, fields map[string]any) {
+	var firstPanic any
+	for _, route := range fol.routes {
+		if l > route.MinLevel {
+			continue
+		}
+		if recovered := route.log(l, msg, fields); recovered != nil && firstPanic == nil {
+			firstPanic = recovered
+		}
+	}
+	if firstPanic != nil && l == Panic {
+		panic(firstPanic)
+	}
+}
+
+// log passes the log message to the route's Logger if the route's Filter
+// accepts it, and returns the value of any panic that occurs
+func (route Route) log(l Level, msg string, fields map[string]any) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+	if route.Filter == nil || route.Filter(fields) {
+		logAt(route.Logger, l, msg, fields)
+	}
+	return
+}
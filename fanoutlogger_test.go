@@ -0,0 +1,83 @@
+package output_test
+
+import (
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+type panickyLogger struct {
+	output.NilLogger
+}
+
+func (panickyLogger) Warning(string, map[string]any) {
+	panic("broken logger")
+}
+
+func (panickyLogger) Panic(msg string, _ map[string]any) {
+	panic(msg)
+}
+
+func TestFanOutLogger(t *testing.T) {
+	file := output.NewRecordingLogger()
+	stderr := output.NewRecordingLogger()
+	audit := output.NewRecordingLogger()
+	fol := output.NewFanOutLogger(
+		output.Route{Logger: panickyLogger{}, MinLevel: output.Trace},
+		output.Route{Logger: file, MinLevel: output.Trace},
+		output.Route{Logger: stderr, MinLevel: output.Warning},
+		output.Route{Logger: audit, MinLevel: output.Trace, Filter: func(fields map[string]any) bool {
+			_, ok := fields["user"]
+			return ok
+		}},
+	)
+	o := output.NewCustomBus(nil, nil, fol)
+	o.Log(output.Trace, "t", nil)
+	o.Log(output.Debug, "d", nil)
+	o.Log(output.Info, "login", map[string]any{"user": "joe"})
+	o.Log(output.Warning, "w", nil)
+	o.Log(output.Error, "e", map[string]any{"user": "ann"})
+	o.Log(output.Fatal, "f", nil)
+	func() {
+		defer func() {
+			if r := recover(); r != "p" {
+				t.Errorf("FanOutLogger.Panic() recovered %v, want %q", r, "p")
+			}
+		}()
+		o.Log(output.Panic, "p", nil)
+	}()
+	tests := map[string]struct {
+		rl   *output.RecordingLogger
+		want string
+	}{
+		"file": {
+			rl: file,
+			want: "level='trace'  msg='t'\n" +
+				"level='debug'  msg='d'\n" +
+				"level='info' user='joe' msg='login'\n" +
+				"level='warning'  msg='w'\n" +
+				"level='error' user='ann' msg='e'\n" +
+				"level='fatal'  msg='f'\n" +
+				"level='panic'  msg='p'\n",
+		},
+		"stderr": {
+			rl: stderr,
+			want: "level='warning'  msg='w'\n" +
+				"level='error' user='ann' msg='e'\n" +
+				"level='fatal'  msg='f'\n" +
+				"level='panic'  msg='p'\n",
+		},
+		"audit": {
+			rl: audit,
+			want: "level='info' user='joe' msg='login'\n" +
+				"level='error' user='ann' msg='e'\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.rl.String(); got != tt.want {
+				t.Errorf("FanOutLogger wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,5 @@ go 1.26
 require (
 	github.com/google/go-cmp v0.7.0
 	github.com/mattn/go-isatty v0.0.20
+	golang.org/x/sys v0.42.0
 )
-
-require golang.org/x/sys v0.42.0 // indirect
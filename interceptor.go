@@ -0,0 +1,87 @@
+package output
+
+import "fmt"
+
+type (
+	// Channel identifies where an Event is headed.
+	Channel uint8
+
+	// Event is a single print or log call, as seen by an Interceptor. Text is
+	// the text to be written: for the console and error channels, it is fully
+	// formatted, including any tab and list decoration and any terminating
+	// newline; for the log channel, it is the log message. Level and Fields
+	// are only meaningful for the log channel.
+	Event struct {
+		Channel Channel
+		Text    string
+		Level   Level
+		Fields  map[string]any
+	}
+
+	// Interceptor sees every print and log call made through a Bus as an
+	// Event, before it reaches the writers or the Logger. An Interceptor
+	// passes the Event along by calling next; it can modify the Event
+	// (including its Channel) before doing so, drop the Event by not calling
+	// next, or duplicate it by calling next more than once.
+	//
+	// Writes made directly to the writers returned by ConsoleWriter and
+	// ErrorWriter are not seen by Interceptors. Events are seen after the Bus
+	// has counted them in its Tally.
+	Interceptor func(e Event, next func(Event))
+)
+
+// These are the Channel values.
+const (
+	ConsoleChannel Channel = iota
+	ErrorChannel
+	LogChannel
+)
+
+var channelNames = []string{
+	ConsoleChannel: "console",
+	ErrorChannel:   "error",
+	LogChannel:     "log",
+}
+
+// String returns the lower-case name of the channel, e.g., "console".
+func (c Channel) String() string {
+	if int(c) < len(channelNames) {
+		return channelNames[c]
+	}
+	return fmt.Sprintf("Channel(%d)", uint8(c))
+}
+
+// WithInterceptors adds Interceptors to the Bus; the first Interceptor sees
+// each Event first.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *busOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithInterceptors adds Interceptors to the Recorder, which then records the
+// Events that emerge from them; the first Interceptor sees each Event first.
+// It returns the Recorder so that calls can be chained onto NewRecorder().
+func (r *Recorder) WithInterceptors(interceptors ...Interceptor) *Recorder {
+	r.interceptors = append(r.interceptors, interceptors...)
+	return r
+}
+
+func (b *bus) emit(e Event) {
+	intercept(b.interceptors, e, b.deliver)
+}
+
+func (r *Recorder) emit(e Event) {
+	intercept(r.interceptors, e, r.deliver)
+}
+
+// intercept passes e through the interceptors, in order, and then to deliver
+func intercept(interceptors []Interceptor, e Event, deliver func(Event)) {
+	if len(interceptors) == 0 {
+		deliver(e)
+		return
+	}
+	interceptors[0](e, func(next Event) {
+		intercept(interceptors[1:], next, deliver)
+	})
+}
@@ -0,0 +1,109 @@
+package output_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestRecorder_WithInterceptors(t *testing.T) {
+	var seen []string
+	observe := func(e output.Event, next func(output.Event)) {
+		seen = append(seen, e.Channel.String()+":"+strings.TrimSuffix(e.Text, "\n"))
+		next(e)
+	}
+	dropSecrets := func(e output.Event, next func(output.Event)) {
+		if !strings.Contains(e.Text, "secret") {
+			next(e)
+		}
+	}
+	shout := func(e output.Event, next func(output.Event)) {
+		if e.Channel == output.ConsoleChannel {
+			e.Text = strings.ToUpper(e.Text)
+		}
+		next(e)
+	}
+	mirrorErrors := func(e output.Event, next func(output.Event)) {
+		next(e)
+		if e.Channel == output.ErrorChannel {
+			next(output.Event{
+				Channel: output.LogChannel,
+				Text:    strings.TrimSpace(e.Text),
+				Level:   output.Error,
+				Fields:  map[string]any{"mirrored": true},
+			})
+		}
+	}
+	o := output.NewRecorder().WithInterceptors(observe, dropSecrets).WithInterceptors(shout, mirrorErrors)
+	o.IncrementTab(2)
+	o.BeginConsoleList(true)
+	o.ConsolePrintln("first")
+	o.ConsolePrintf("the secret is %d\n", 42)
+	o.ConsolePrintln("third")
+	o.ErrorPrintf("oops: %s\n", "no luck")
+	o.Log(output.Info, "done", map[string]any{"count": 2})
+	o.Log(output.Level(99), "bad level", nil)
+	o.Report(t, "WithInterceptors()", output.WantedRecording{
+		Console: "   1. FIRST\n   3. THIRD\n",
+		Error: "oops: no luck\n" +
+			"Programming error: call to Recorder.Log() with invalid level value 99; message: 'bad level', args: 'map[]'.\n",
+		Log: "level='error' mirrored='true' msg='oops: no luck'\n" +
+			"level='info' count='2' msg='done'\n" +
+			"level='error' mirrored='true' msg='Programming error: call to Recorder.Log() with invalid level value 99;" +
+			" message: 'bad level', args: 'map[]'.'\n",
+	})
+	want := []string{
+		"console:   1. first",
+		"console:   2. the secret is 42",
+		"console:   3. third",
+		"error:oops: no luck",
+		"log:done",
+		"log:bad level",
+		"error:Programming error: call to Recorder.Log() with invalid level value 99; message: 'bad level', args: 'map[]'.",
+	}
+	if strings.Join(seen, "|") != strings.Join(want, "|") {
+		t.Errorf("interceptor saw %q, want %q", seen, want)
+	}
+}
+
+func TestWithInterceptors(t *testing.T) {
+	console := &bytes.Buffer{}
+	errorOutput := &bytes.Buffer{}
+	o := output.NewBus(
+		output.WithConsoleWriter(console),
+		output.WithErrorWriter(errorOutput),
+		output.WithInterceptors(func(e output.Event, next func(output.Event)) {
+			next(e)
+			if e.Channel == output.ConsoleChannel {
+				// keep a transcript of console output on the error channel
+				e.Channel = output.ErrorChannel
+				next(e)
+			}
+		}),
+	)
+	o.ConsolePrintln("hello")
+	o.ErrorPrintln("problem")
+	_, _ = o.ConsoleWriter().Write([]byte("direct\n"))
+	if got := console.String(); got != "hello\ndirect\n" {
+		t.Errorf("WithInterceptors() console output = %q", got)
+	}
+	if got := errorOutput.String(); got != "hello\nproblem\n" {
+		t.Errorf("WithInterceptors() error output = %q", got)
+	}
+}
+
+func TestChannel_String(t *testing.T) {
+	tests := map[output.Channel]string{
+		output.ConsoleChannel: "console",
+		output.ErrorChannel:   "error",
+		output.LogChannel:     "log",
+		output.Channel(7):     "Channel(7)",
+	}
+	for c, want := range tests {
+		if got := c.String(); got != want {
+			t.Errorf("Channel.String() = %q, want %q", got, want)
+		}
+	}
+}
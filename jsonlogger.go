@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type (
+	// JSONLogger is a Logger that writes each log message to an io.Writer as a
+	// single line containing a JSON object, e.g.:
+	//
+	//	{"time":"2026-10-18T09:15:02Z","level":"warning","msg":"disk low","fields":{"free":10}}
+	//
+	// Field values are encoded safely: error values are written as their
+	// messages, time.Time values are written per RFC 3339, and values of types
+	// that JSON cannot represent are converted with fmt.
+	//
+	// Like most production loggers, JSONLogger calls panic() after writing a
+	// panic log message, and exits the program after writing a fatal log
+	// message.
+	JSONLogger struct {
+		lock   sync.Mutex
+		writer io.Writer
+		clock  Clock
+	}
+
+	jsonEntry struct {
+		Time   string         `json:"time"`
+		Level  string         `json:"level"`
+		Msg    string         `json:"msg"`
+		Fields map[string]any `json:"fields,omitempty"`
+	}
+)
+
+// NewJSONLogger returns a JSONLogger that writes to w.
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{writer: w}
+}
+
+// WithClock sets the Clock that the JSONLogger uses to timestamp log messages;
+// it returns the JSONLogger so that calls can be chained onto NewJSONLogger().
+func (jl *JSONLogger) WithClock(c Clock) *JSONLogger {
+	jl.clock = c
+	return jl
+}
+
+// Trace writes a trace log message.
+func (jl *JSONLogger) Trace(msg string, fields map[string]any) {
+	jl.log(Trace, msg, fields)
+}
+
+// Debug writes a debug log message.
+func (jl *JSONLogger) Debug(msg string, fields map[string]any) {
+	jl.log(Debug, msg, fields)
+}
+
+// Info writes an info log message.
+func (jl *JSONLogger) Info(msg string, fields map[string]any) {
+	jl.log(Info, msg, fields)
+}
+
+// Warning writes a warning log message.
+func (jl *JSONLogger) Warning(msg string, fields map[string]any) {
+	jl.log(Warning, msg, fields)
+}
+
+// Error writes an error log message.
+func (jl *JSONLogger) Error(msg string, fields map[string]any) {
+	jl.log(Error, msg, fields)
+}
+
+// Panic writes a panic log message and then calls panic().
+func (jl *JSONLogger) Panic(msg string, fields map[string]any) {
+	jl.log(Panic, msg, fields)
+}
+
+// Fatal writes a fatal log message and then exits the program.
+func (jl *JSONLogger) Fatal(msg string, fields map[string]any) {
+	jl.log(Fatal, msg, fields)
+}
+
+func (jl *JSONLogger) log(l Level, msg string, fields map[string]any) {
+	entry := jsonEntry{
+		Time:  jl.clock.now().Format(time.RFC3339Nano),
+		Level: l.String(),
+		Msg:   msg,
+	}
+	if len(fields) > 0 {
+		entry.Fields = make(map[string]any, len(fields))
+		for k, v := range fields {
+			entry.Fields[k] = encodableValue(v)
+		}
+	}
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(entry) // cannot fail: every value has been made encodable
+	jl.lock.Lock()
+	_, _ = jl.writer.Write(buffer.Bytes())
+	jl.lock.Unlock()
+	terminate(l, msg)
+}
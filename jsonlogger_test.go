@@ -0,0 +1,130 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type jsonValue struct{}
+
+func (jsonValue) MarshalJSON() ([]byte, error) {
+	return []byte(`{"custom":true}`), nil
+}
+
+type badJSONValue struct{}
+
+func (badJSONValue) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+func (badJSONValue) String() string {
+	return "bad value"
+}
+
+func fixedClock() time.Time {
+	return time.Date(2026, 10, 18, 9, 15, 2, 0, time.UTC)
+}
+
+func TestJSONLogger(t *testing.T) {
+	tests := map[string]struct {
+		l      Level
+		msg    string
+		fields map[string]any
+		want   string
+	}{
+		"no fields": {
+			l:    Info,
+			msg:  "hello <world>",
+			want: `{"time":"2026-10-18T09:15:02Z","level":"info","msg":"hello <world>"}` + "\n",
+		},
+		"values": {
+			l:   Warning,
+			msg: "disk low",
+			fields: map[string]any{
+				"free":     10,
+				"ratio":    0.5,
+				"nan":      math.NaN(),
+				"ok":       false,
+				"nil":      nil,
+				"err":      errors.New("oops"),
+				"when":     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				"wait":     time.Second,
+				"custom":   jsonValue{},
+				"bad":      badJSONValue{},
+				"struct":   struct{ A int }{A: 1},
+				"strings":  []string{"a", "b"},
+				"chain":    ErrorChain{"a", "b"},
+				"float32":  float32(1.5),
+				"smallInt": int8(-3),
+			},
+			want: `{"time":"2026-10-18T09:15:02Z","level":"warning","msg":"disk low","fields":{` +
+				`"bad":"bad value","chain":["a","b"],"custom":{"custom":true},"err":"oops",` +
+				`"float32":1.5,"free":10,"nan":"NaN","nil":null,"ok":false,"ratio":0.5,"smallInt":-3,` +
+				`"strings":"[a b]","struct":"{1}","wait":"1s","when":"2026-01-02T03:04:05Z"}}` + "\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			jl := NewJSONLogger(w).WithClock(fixedClock)
+			NewCustomBus(nil, nil, jl).Log(tt.l, tt.msg, tt.fields)
+			if got := w.String(); got != tt.want {
+				t.Errorf("JSONLogger wrote %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLogger_Levels(t *testing.T) {
+	w := &bytes.Buffer{}
+	jl := NewJSONLogger(w).WithClock(fixedClock)
+	jl.Trace("t", nil)
+	jl.Debug("d", nil)
+	jl.Info("i", nil)
+	jl.Warning("w", nil)
+	jl.Error("e", nil)
+	want := `{"time":"2026-10-18T09:15:02Z","level":"trace","msg":"t"}` + "\n" +
+		`{"time":"2026-10-18T09:15:02Z","level":"debug","msg":"d"}` + "\n" +
+		`{"time":"2026-10-18T09:15:02Z","level":"info","msg":"i"}` + "\n" +
+		`{"time":"2026-10-18T09:15:02Z","level":"warning","msg":"w"}` + "\n" +
+		`{"time":"2026-10-18T09:15:02Z","level":"error","msg":"e"}` + "\n"
+	if got := w.String(); got != want {
+		t.Errorf("JSONLogger wrote %s, want %s", got, want)
+	}
+	if NewJSONLogger(w).clock.now().IsZero() {
+		t.Errorf("JSONLogger default clock returned zero time")
+	}
+}
+
+func TestJSONLogger_Terminal(t *testing.T) {
+	savedExit := exitProgram
+	defer func() {
+		exitProgram = savedExit
+	}()
+	var exitCode int
+	exitProgram = func(code int) {
+		exitCode = code
+	}
+	w := &bytes.Buffer{}
+	jl := NewJSONLogger(w).WithClock(fixedClock)
+	jl.Fatal("bye", nil)
+	if exitCode != 1 {
+		t.Errorf("JSONLogger.Fatal() exit code %d, want 1", exitCode)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("JSONLogger.Panic() recovered %v, want %q", r, "boom")
+			}
+		}()
+		jl.Panic("boom", nil)
+	}()
+	want := `{"time":"2026-10-18T09:15:02Z","level":"fatal","msg":"bye"}` + "\n" +
+		`{"time":"2026-10-18T09:15:02Z","level":"panic","msg":"boom"}` + "\n"
+	if got := w.String(); got != want {
+		t.Errorf("JSONLogger wrote %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// LineWriter is an io.Writer that splits what is written to it into lines and
+// passes each line, without its line terminator, to a Bus function; a final
+// line without a terminator is held until more is written, or until Flush or
+// Close is called. LineWriter is safe for concurrent use.
+type LineWriter struct {
+	lock    sync.Mutex
+	pending []byte
+	emit    func(string)
+}
+
+var levelTag = regexp.MustCompile(
+	`(?i)^\s*(?:\[(trace|debug|info|warn|warning|error|err|panic|fatal)\]|(trace|debug|info|warn|warning|error|err|panic|fatal):)\s*`)
+
+// NewLogWriter returns a LineWriter that logs each line on b at the specified
+// level. If parseLevels is true, a level tag at the start of a line, such as
+// "[WARN]" or "error:" (in any case), overrides the level and is removed from
+// the message; tags for the panic and fatal levels are logged at the Error
+// level, so that text from another source cannot make the program panic or
+// exit. Blank lines are not logged.
+func NewLogWriter(b Bus, l Level, parseLevels bool) *LineWriter {
+	return &LineWriter{emit: func(line string) {
+		if strings.TrimSpace(line) == "" {
+			return
+		}
+		level := l
+		if parseLevels {
+			level, line = parseLevelTag(l, line)
+		}
+		b.Log(level, line, nil)
+	}}
+}
+
+// NewStdLogger returns a standard library *log.Logger whose output is logged on
+// b, as by a LineWriter created by NewLogWriter; the *log.Logger has no prefix
+// and no flags, so that level tags are found at the start of each line.
+func NewStdLogger(b Bus, l Level, parseLevels bool) *log.Logger {
+	return log.New(NewLogWriter(b, l, parseLevels), "", 0)
+}
+
+// NewConsoleLineWriter returns a LineWriter that prints each line on b's
+// console channel with ConsolePrintln, and so with the current tab and list
+// decoration; it can, for example, be used as a subprocess's stdout.
+func NewConsoleLineWriter(b Bus) *LineWriter {
+	return &LineWriter{emit: b.ConsolePrintln}
+}
+
+// NewErrorLineWriter returns a LineWriter that prints each line on b's error
+// channel with ErrorPrintln, and so with the current list decoration; it can,
+// for example, be used as a subprocess's stderr.
+func NewErrorLineWriter(b Bus) *LineWriter {
+	return &LineWriter{emit: b.ErrorPrintln}
+}
+
+func parseLevelTag(defaultLevel Level, line string) (Level, string) {
+	match := levelTag.FindStringSubmatch(line)
+	if match == nil {
+		return defaultLevel, line
+	}
+	name := strings.ToLower(match[1] + match[2])
+	level := Error
+	switch name {
+	case "trace":
+		level = Trace
+	case "debug":
+		level = Debug
+	case "info":
+		level = Info
+	case "warn", "warning":
+		level = Warning
+	}
+	return level, line[len(match[0]):]
+}
+
+// Write passes each complete line in p to the LineWriter's Bus function; it
+// always returns len(p), nil.
+func (lw *LineWriter) Write(p []byte) (int, error) {
+	lw.lock.Lock()
+	lw.pending = append(lw.pending, p...)
+	var lines []string
+	for {
+		i := bytes.IndexByte(lw.pending, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, strings.TrimSuffix(string(lw.pending[:i]), "\r"))
+		lw.pending = lw.pending[i+1:]
+	}
+	lw.lock.Unlock()
+	for _, line := range lines {
+		lw.emit(line)
+	}
+	return len(p), nil
+}
+
+// Flush passes any incomplete final line to the LineWriter's Bus function.
+func (lw *LineWriter) Flush() {
+	lw.lock.Lock()
+	pending := lw.pending
+	lw.pending = nil
+	lw.lock.Unlock()
+	if len(pending) > 0 {
+		lw.emit(strings.TrimSuffix(string(pending), "\r"))
+	}
+}
+
+// Close flushes the LineWriter; it always returns nil.
+func (lw *LineWriter) Close() error {
+	lw.Flush()
+	return nil
+}
@@ -0,0 +1,92 @@
+package output_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestNewLogWriter(t *testing.T) {
+	tests := map[string]struct {
+		parseLevels bool
+		writes      []string
+		want        string
+	}{
+		"plain": {
+			writes: []string{"first line\r\nsecond ", "line\n\n   \nunterminated"},
+			want: "level='info'  msg='first line'\n" +
+				"level='info'  msg='second line'\n" +
+				"level='info'  msg='unterminated'\n",
+		},
+		"parsed": {
+			parseLevels: true,
+			writes: []string{
+				"[WARN] low disk\n",
+				"  [Debug]details\n",
+				"error: failed\n",
+				"[FATAL] not really\n",
+				"panic: nor this\n",
+				"TRACE: t\n",
+				"[info] i\n",
+				"[ERR] e\n",
+				"warning: w\n",
+				"no tag: here\n",
+			},
+			want: "level='warning'  msg='low disk'\n" +
+				"level='debug'  msg='details'\n" +
+				"level='error'  msg='failed'\n" +
+				"level='error'  msg='not really'\n" +
+				"level='error'  msg='nor this'\n" +
+				"level='trace'  msg='t'\n" +
+				"level='info'  msg='i'\n" +
+				"level='error'  msg='e'\n" +
+				"level='warning'  msg='w'\n" +
+				"level='info'  msg='no tag: here'\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := output.NewRecorder()
+			lw := output.NewLogWriter(o, output.Info, tt.parseLevels)
+			for _, s := range tt.writes {
+				if n, err := fmt.Fprint(lw, s); n != len(s) || err != nil {
+					t.Errorf("LineWriter.Write() = %d, %v", n, err)
+				}
+			}
+			if err := lw.Close(); err != nil {
+				t.Errorf("LineWriter.Close() error = %v", err)
+			}
+			lw.Flush()
+			o.Report(t, "NewLogWriter()", output.WantedRecording{Log: tt.want})
+		})
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	o := output.NewRecorder()
+	l := output.NewStdLogger(o, output.Info, true)
+	l.Printf("[WARN] %d files skipped", 3)
+	l.Println("done")
+	o.Report(t, "NewStdLogger()", output.WantedRecording{
+		Log: "level='warning'  msg='3 files skipped'\nlevel='info'  msg='done'\n",
+	})
+}
+
+func TestNewConsoleLineWriter(t *testing.T) {
+	o := output.NewRecorder()
+	o.IncrementTab(2)
+	o.BeginConsoleList(true)
+	o.BeginErrorList(false)
+	cw := output.NewConsoleLineWriter(o)
+	ew := output.NewErrorLineWriter(o)
+	_, _ = fmt.Fprint(cw, "alpha\nbeta\ngam")
+	_, _ = fmt.Fprint(ew, "oops\n")
+	_, _ = fmt.Fprint(cw, "ma\n")
+	_ = cw.Close()
+	_ = ew.Close()
+	o.Report(t, "NewConsoleLineWriter()", output.WantedRecording{
+		Console: "   1. alpha\n   2. beta\n   3. gamma\n",
+		Error:   "● oops\n",
+	})
+}
@@ -7,6 +7,7 @@ type ListDecorator struct {
 	enabled    bool
 	numeric    bool
 	itemNumber uint8
+	bullet     string
 }
 
 func newListDecorator(enabled, numeric bool) *ListDecorator {
@@ -17,15 +18,33 @@ func newListDecorator(enabled, numeric bool) *ListDecorator {
 	}
 }
 
+// withBullet sets the bullet used by a non-numeric list; the default bullet is
+// used if bullet is empty
+func (ld *ListDecorator) withBullet(bullet string) *ListDecorator {
+	ld.bullet = bullet
+	return ld
+}
+
 // Decorator generates the appropriate decoration for lists (and typically, this is the empty string)
 func (ld *ListDecorator) Decorator() string {
-	if !ld.enabled {
+	s := ld.peek()
+	if ld.enabled && ld.numeric {
+		ld.itemNumber++
+	}
+	return s
+}
+
+// peek returns the decoration that the next call to Decorator will generate,
+// without advancing the item number; a nil ListDecorator generates nothing
+func (ld *ListDecorator) peek() string {
+	if ld == nil || !ld.enabled {
 		return ""
 	}
 	if ld.numeric {
-		s := fmt.Sprintf("%2d. ", ld.itemNumber)
-		ld.itemNumber++
-		return s
+		return fmt.Sprintf("%2d. ", ld.itemNumber)
+	}
+	if ld.bullet != "" {
+		return ld.bullet + " "
 	}
 	return "● "
 }
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// LogfmtLogger is a Logger that writes each log message to an io.Writer as a
+// single logfmt line, e.g.:
+//
+//	ts=2026-10-18T09:15:02Z level=warning msg="disk low" free=10 path=/var
+//
+// Fields follow the ts, level, and msg keys in key order. Values that are empty
+// or that contain spaces, quotes, equal signs, or control characters (such as
+// the newlines in a multi-line message) are quoted and escaped as Go string
+// literals, so that every line can be parsed back. Field values are converted
+// the same way that JSONLogger converts them: error values are written as their
+// messages, time.Time values are written per RFC 3339, and other values are
+// converted with fmt.
+//
+// Like most production loggers, LogfmtLogger calls panic() after writing a
+// panic log message, and exits the program after writing a fatal log message.
+type LogfmtLogger struct {
+	lock   sync.Mutex
+	writer io.Writer
+	clock  Clock
+}
+
+// NewLogfmtLogger returns a LogfmtLogger that writes to w.
+func NewLogfmtLogger(w io.Writer) *LogfmtLogger {
+	return &LogfmtLogger{writer: w}
+}
+
+// WithClock sets the Clock that the LogfmtLogger uses to timestamp log
+// messages; it returns the LogfmtLogger so that calls can be chained onto
+// NewLogfmtLogger().
+func (ll *LogfmtLogger) WithClock(c Clock) *LogfmtLogger {
+	ll.clock = c
+	return ll
+}
+
+// Trace writes a trace log message.
+func (ll *LogfmtLogger) Trace(msg string, fields map[string]any) {
+	ll.log(Trace, msg, fields)
+}
+
+// Debug writes a debug log message.
+func (ll *LogfmtLogger) Debug(msg string, fields map[string]any) {
+	ll.log(Debug, msg, fields)
+}
+
+// Info writes an info log message.
+func (ll *LogfmtLogger) Info(msg string, fields map[string]any) {
+	ll.log(Info, msg, fields)
+}
+
+// Warning writes a warning log message.
+func (ll *LogfmtLogger) Warning(msg string, fields map[string]any) {
+	ll.log(Warning, msg, fields)
+}
+
+// Error writes an error log message.
+func (ll *LogfmtLogger) Error(msg string, fields map[string]any) {
+	ll.log(Error, msg, fields)
+}
+
+// Panic writes a panic log message and then calls panic().
+func (ll *LogfmtLogger) Panic(msg string, fields map[string]any) {
+	ll.log(Panic, msg, fields)
+}
+
+// Fatal writes a fatal log message and then exits the program.
+func (ll *LogfmtLogger) Fatal(msg string, fields map[string]any) {
+	ll.log(Fatal, msg, fields)
+}
+
+func (ll *LogfmtLogger) log(l Level, msg string, fields map[string]any) {
+	line := &strings.Builder{}
+	writeLogfmtPair(line, "ts", ll.clock.now().Format(time.RFC3339Nano))
+	writeLogfmtPair(line, "level", l.String())
+	writeLogfmtPair(line, "msg", msg)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		writeLogfmtPair(line, logfmtKey(k), logfmtValue(fields[k]))
+	}
+	line.WriteByte('\n')
+	ll.lock.Lock()
+	_, _ = io.WriteString(ll.writer, line.String())
+	ll.lock.Unlock()
+	terminate(l, msg)
+}
+
+func writeLogfmtPair(line *strings.Builder, key, value string) {
+	if line.Len() > 0 {
+		line.WriteByte(' ')
+	}
+	line.WriteString(key)
+	line.WriteByte('=')
+	if logfmtNeedsQuotes(value) {
+		line.WriteString(strconv.Quote(value))
+	} else {
+		line.WriteString(value)
+	}
+}
+
+func logfmtNeedsQuotes(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// logfmtKey replaces the characters that cannot appear in a logfmt key with
+// underscores
+func logfmtKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+func logfmtValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	case error:
+		return fmt.Sprint(value)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,90 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogfmtLogger(t *testing.T) {
+	tests := map[string]struct {
+		l      Level
+		msg    string
+		fields map[string]any
+		want   string
+	}{
+		"simple": {
+			l:    Info,
+			msg:  "started",
+			want: "ts=2026-10-18T09:15:02Z level=info msg=started\n",
+		},
+		"quoting": {
+			l:   Warning,
+			msg: "disk \"almost\" full",
+			fields: map[string]any{
+				"path":    "/var/log",
+				"empty":   "",
+				"spaced":  "a b",
+				"equals":  "a=b",
+				"slash":   `C:\temp`,
+				"count":   10,
+				"nil":     nil,
+				"err":     errors.New("no space left"),
+				"when":    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				"bad key": true,
+				"":        "anonymous",
+				"unicode": "héllo",
+			},
+			want: `ts=2026-10-18T09:15:02Z level=warning msg="disk \"almost\" full" _=anonymous bad_key=true ` +
+				`count=10 empty="" equals="a=b" err="no space left" nil=null path=/var/log slash="C:\\temp" ` +
+				`spaced="a b" unicode=héllo when=2026-01-02T03:04:05Z` + "\n",
+		},
+		"multi-line": {
+			l:    Error,
+			msg:  "failed:\n\tline 1\n\tline 2",
+			want: `ts=2026-10-18T09:15:02Z level=error msg="failed:\n\tline 1\n\tline 2"` + "\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			NewCustomBus(nil, nil, NewLogfmtLogger(w).WithClock(fixedClock)).Log(tt.l, tt.msg, tt.fields)
+			if got := w.String(); got != tt.want {
+				t.Errorf("LogfmtLogger wrote %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogfmtLogger_Levels(t *testing.T) {
+	savedExit := exitProgram
+	defer func() {
+		exitProgram = savedExit
+	}()
+	exitProgram = func(int) {}
+	w := &bytes.Buffer{}
+	ll := NewLogfmtLogger(w).WithClock(fixedClock)
+	ll.Trace("t", nil)
+	ll.Debug("d", nil)
+	ll.Info("i", nil)
+	ll.Warning("w", nil)
+	ll.Error("e", nil)
+	ll.Fatal("f", nil)
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		ll.Panic("p", nil)
+	}()
+	want := "ts=2026-10-18T09:15:02Z level=trace msg=t\n" +
+		"ts=2026-10-18T09:15:02Z level=debug msg=d\n" +
+		"ts=2026-10-18T09:15:02Z level=info msg=i\n" +
+		"ts=2026-10-18T09:15:02Z level=warning msg=w\n" +
+		"ts=2026-10-18T09:15:02Z level=error msg=e\n" +
+		"ts=2026-10-18T09:15:02Z level=fatal msg=f\n" +
+		"ts=2026-10-18T09:15:02Z level=panic msg=p\n"
+	if got := w.String(); got != want {
+		t.Errorf("LogfmtLogger wrote %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Clock returns the current time; Loggers and other types that record the
+// time accept a Clock so that tests can control the time they record.
+type Clock func() time.Time
+
+// var so testing can replace
+var exitProgram = os.Exit
+
+func (c Clock) now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c()
+}
+
+// terminate provides the production behavior of panic and fatal log messages,
+// once they have been written: a panic log message calls panic() with the
+// message, and a fatal log message exits the program with status 1.
+func terminate(l Level, msg string) {
+	switch l {
+	case Panic:
+		panic(msg)
+	case Fatal:
+		exitProgram(1)
+	}
+}
+
+// encodableValue converts a field value into a value that encoding/json
+// represents faithfully: errors are represented by their messages, times are
+// formatted per RFC 3339, json.Numbers remain numbers, and values of
+// unsupported types are converted with fmt.
+func encodableValue(v any) any {
+	switch value := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return value
+	case float32:
+		return encodableFloat(float64(value), v)
+	case float64:
+		return encodableFloat(value, v)
+	case error:
+		return fmt.Sprint(value)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case json.Number:
+		// as decoded by a json.Decoder that uses numbers, e.g., by Replay
+		if data, err := json.Marshal(value); err == nil {
+			return json.RawMessage(data)
+		}
+		return string(value)
+	case json.Marshaler:
+		if data, err := json.Marshal(value); err == nil {
+			return json.RawMessage(data)
+		}
+		return fmt.Sprintf("%v", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func encodableFloat(f float64, v any) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprintf("%v", v)
+	}
+	return v
+}
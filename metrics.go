@@ -0,0 +1,162 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+type (
+	// Metrics counts the messages, and their bytes, written through a Bus:
+	// per channel, and, for log messages, per level. The bytes of a console or
+	// error message are those of its fully formatted text; the bytes of a log
+	// message are those of its message, not including its fields. Log
+	// messages discarded because of the Bus's log level (see WithLogLevel),
+	// and direct writes to the writers returned by ConsoleWriter and
+	// ErrorWriter, are not counted; panic and fatal log messages are counted
+	// before they are passed to the Logger, which may panic or exit the
+	// program.
+	//
+	// Instrument a Bus with the WithMetrics Option, or with Attach. Metrics is
+	// safe for concurrent use, and needs no external dependencies: read it
+	// with Snapshot, or write it in the Prometheus text exposition format with
+	// WritePrometheus. *Metrics is an expvar.Var, as its String method
+	// returns its Snapshot as JSON, so it can be published as an expvar
+	// variable:
+	//
+	//	expvar.Publish("output", m)
+	Metrics struct {
+		channels [LogChannel + 1]metricCounters
+		levels   [Trace + 1]metricCounters
+	}
+
+	metricCounters struct {
+		messages atomic.Uint64
+		bytes    atomic.Uint64
+	}
+
+	// Counts is a number of messages and the number of bytes they contained.
+	Counts struct {
+		Messages uint64 `json:"messages"`
+		Bytes    uint64 `json:"bytes"`
+	}
+
+	// MetricsSnapshot is the state of a Metrics at a point in time; Channels
+	// is keyed by channel name (see Channel.String()), and LogLevels by level
+	// name (see Level.String()).
+	MetricsSnapshot struct {
+		Channels  map[string]Counts `json:"channels"`
+		LogLevels map[string]Counts `json:"log_levels"`
+	}
+)
+
+// NewMetrics returns a Metrics with all counts at zero.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// WithMetrics makes the Bus count its output in m.
+func WithMetrics(m *Metrics) Option {
+	return func(o *busOptions) {
+		o.metrics = m
+	}
+}
+
+// Attach makes b (or the Bus it wraps; see Subscribe) count its output in the
+// Metrics; call detach to stop counting. ok is false if b does not support
+// subscriptions.
+func (m *Metrics) Attach(b Bus) (detach func(), ok bool) {
+	return Subscribe(b, nil, m.observe)
+}
+
+func (m *Metrics) observe(e Event) error {
+	size := uint64(len(e.Text))
+	if int(e.Channel) < len(m.channels) {
+		m.channels[e.Channel].add(size)
+	}
+	if e.Channel == LogChannel && int(e.Level) < len(m.levels) {
+		m.levels[e.Level].add(size)
+	}
+	return nil
+}
+
+func (mc *metricCounters) add(size uint64) {
+	mc.messages.Add(1)
+	mc.bytes.Add(size)
+}
+
+func (mc *metricCounters) counts() Counts {
+	return Counts{Messages: mc.messages.Load(), Bytes: mc.bytes.Load()}
+}
+
+// Snapshot returns the current counts; every channel and every level is
+// present, even if its counts are zero.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	snapshot := MetricsSnapshot{
+		Channels:  make(map[string]Counts, len(m.channels)),
+		LogLevels: make(map[string]Counts, len(m.levels)),
+	}
+	for c := range m.channels {
+		snapshot.Channels[Channel(c).String()] = m.channels[c].counts()
+	}
+	for l := range m.levels {
+		snapshot.LogLevels[Level(l).String()] = m.levels[l].counts()
+	}
+	return snapshot
+}
+
+// String returns the current counts, as returned by Snapshot, encoded as JSON;
+// this makes *Metrics satisfy expvar.Var.
+func (m *Metrics) String() string {
+	data, _ := json.Marshal(m.Snapshot())
+	return string(data)
+}
+
+// WritePrometheus writes the current counts to w in the Prometheus text
+// exposition format, as four counters whose names begin with namespace ("output"
+// if namespace is empty):
+//
+//	<namespace>_messages_total{channel="console"}
+//	<namespace>_bytes_total{channel="console"}
+//	<namespace>_log_messages_total{level="error"}
+//	<namespace>_log_bytes_total{level="error"}
+func (m *Metrics) WritePrometheus(w io.Writer, namespace string) error {
+	if namespace == "" {
+		namespace = "output"
+	}
+	builder := &strings.Builder{}
+	channelLabels := make([]string, len(m.channels))
+	channelCounts := make([]Counts, len(m.channels))
+	for c := range m.channels {
+		channelLabels[c] = fmt.Sprintf("channel=%q", Channel(c).String())
+		channelCounts[c] = m.channels[c].counts()
+	}
+	levelLabels := make([]string, len(m.levels))
+	levelCounts := make([]Counts, len(m.levels))
+	for l := range m.levels {
+		levelLabels[l] = fmt.Sprintf("level=%q", Level(l).String())
+		levelCounts[l] = m.levels[l].counts()
+	}
+	writeCounter(builder, namespace+"_messages_total", "Messages written, by channel.", channelLabels,
+		channelCounts, false)
+	writeCounter(builder, namespace+"_bytes_total", "Bytes written, by channel.", channelLabels, channelCounts, true)
+	writeCounter(builder, namespace+"_log_messages_total", "Log messages written, by level.", levelLabels,
+		levelCounts, false)
+	writeCounter(builder, namespace+"_log_bytes_total", "Bytes of log messages written, by level.", levelLabels,
+		levelCounts, true)
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func writeCounter(builder *strings.Builder, name, help string, labels []string, counts []Counts, bytes bool) {
+	fmt.Fprintf(builder, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for i, label := range labels {
+		value := counts[i].Messages
+		if bytes {
+			value = counts[i].Bytes
+		}
+		fmt.Fprintf(builder, "%s{%s} %d\n", name, label, value)
+	}
+}
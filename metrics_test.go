@@ -0,0 +1,153 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestMetrics(t *testing.T) {
+	m := output.NewMetrics()
+	o := output.NewBus(output.WithConsoleWriter(&bytes.Buffer{}), output.WithErrorWriter(&bytes.Buffer{}),
+		output.WithMetrics(m))
+	o.ConsolePrintln("hello")
+	o.ConsolePrintf("%d\n", 42)
+	o.ErrorPrintln("oops")
+	o.Log(output.Error, "broken", map[string]any{"ignored": true})
+	o.Log(output.Warning, "careful", nil)
+	o.Log(output.Warning, "again", nil)
+	_, _ = o.ConsoleWriter().Write([]byte("not counted\n"))
+	snapshot := m.Snapshot()
+	wantChannels := map[string]output.Counts{
+		"console": {Messages: 2, Bytes: 9},
+		"error":   {Messages: 1, Bytes: 5},
+		"log":     {Messages: 3, Bytes: 18},
+	}
+	for name, want := range wantChannels {
+		if got := snapshot.Channels[name]; got != want {
+			t.Errorf("Snapshot() channel %s = %+v, want %+v", name, got, want)
+		}
+	}
+	if got, want := snapshot.LogLevels["warning"], (output.Counts{Messages: 2, Bytes: 12}); got != want {
+		t.Errorf("Snapshot() warning = %+v, want %+v", got, want)
+	}
+	if got := snapshot.LogLevels["trace"]; got != (output.Counts{}) {
+		t.Errorf("Snapshot() trace = %+v, want zero", got)
+	}
+	if len(snapshot.LogLevels) != 7 {
+		t.Errorf("Snapshot() has %d levels, want 7", len(snapshot.LogLevels))
+	}
+}
+
+func TestMetrics_Attach(t *testing.T) {
+	m := output.NewMetrics()
+	o := output.NewRecorder()
+	detach, ok := m.Attach(output.NewCapturingBus(o, &bytes.Buffer{}))
+	if !ok {
+		t.Fatalf("Attach() failed")
+	}
+	o.ErrorPrintln("counted")
+	detach()
+	o.ErrorPrintln("not counted")
+	if got := m.Snapshot().Channels["error"]; got != (output.Counts{Messages: 1, Bytes: 8}) {
+		t.Errorf("Snapshot() error = %+v", got)
+	}
+}
+
+func TestMetrics_String(t *testing.T) {
+	m := output.NewMetrics()
+	o := output.NewRecorder()
+	m.Attach(o)
+	o.Log(output.Info, "hi", nil)
+	// this is what expvar reports for a published *Metrics
+	var decoded output.MetricsSnapshot
+	if err := json.Unmarshal([]byte(m.String()), &decoded); err != nil {
+		t.Fatalf("String() does not decode: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, m.Snapshot()) {
+		t.Errorf("String() decodes to %+v, want %+v", decoded, m.Snapshot())
+	}
+	if got := decoded.LogLevels["info"]; got != (output.Counts{Messages: 1, Bytes: 2}) {
+		t.Errorf("decoded info = %+v", got)
+	}
+}
+
+func TestMetrics_WritePrometheus(t *testing.T) {
+	m := output.NewMetrics()
+	o := output.NewRecorder()
+	m.Attach(o)
+	o.ConsolePrintln("hello")
+	o.Log(output.Error, "broken", nil)
+	w := &bytes.Buffer{}
+	if err := m.WritePrometheus(w, ""); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	want := []string{
+		"# HELP output_messages_total Messages written, by channel.",
+		"# TYPE output_messages_total counter",
+		`output_messages_total{channel="console"} 1`,
+		`output_messages_total{channel="error"} 0`,
+		`output_messages_total{channel="log"} 1`,
+		"# HELP output_bytes_total Bytes written, by channel.",
+		"# TYPE output_bytes_total counter",
+		`output_bytes_total{channel="console"} 6`,
+		`output_bytes_total{channel="error"} 0`,
+		`output_bytes_total{channel="log"} 6`,
+		"# HELP output_log_messages_total Log messages written, by level.",
+		"# TYPE output_log_messages_total counter",
+		`output_log_messages_total{level="fatal"} 0`,
+		`output_log_messages_total{level="panic"} 0`,
+		`output_log_messages_total{level="error"} 1`,
+		`output_log_messages_total{level="warning"} 0`,
+		`output_log_messages_total{level="info"} 0`,
+		`output_log_messages_total{level="debug"} 0`,
+		`output_log_messages_total{level="trace"} 0`,
+		"# HELP output_log_bytes_total Bytes of log messages written, by level.",
+		"# TYPE output_log_bytes_total counter",
+		`output_log_bytes_total{level="fatal"} 0`,
+		`output_log_bytes_total{level="panic"} 0`,
+		`output_log_bytes_total{level="error"} 6`,
+		`output_log_bytes_total{level="warning"} 0`,
+		`output_log_bytes_total{level="info"} 0`,
+		`output_log_bytes_total{level="debug"} 0`,
+		`output_log_bytes_total{level="trace"} 0`,
+	}
+	if got := w.String(); got != strings.Join(want, "\n")+"\n" {
+		t.Errorf("WritePrometheus() = %s", got)
+	}
+	w.Reset()
+	_ = m.WritePrometheus(w, "myapp")
+	if !strings.Contains(w.String(), `myapp_log_bytes_total{level="error"} 6`) {
+		t.Errorf("WritePrometheus() with namespace = %s", w.String())
+	}
+}
+
+func TestMetrics_levels(t *testing.T) {
+	m := output.NewMetrics()
+	logger := output.NewMockLogger(t).Expect(output.Warning, "kept", nil).Expect(output.Panic, "dying", nil).
+		PanicOnPanic()
+	o := output.NewBus(output.WithLogger(logger), output.WithLogLevel(output.Warning), output.WithMetrics(m))
+	o.Log(output.Debug, "discarded", nil)
+	o.Log(output.Warning, "kept", nil)
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		o.Log(output.Panic, "dying", nil)
+	}()
+	logger.AssertExpectations()
+	snapshot := m.Snapshot()
+	if got := snapshot.LogLevels["debug"]; got != (output.Counts{}) {
+		t.Errorf("Snapshot() counted a discarded debug message: %+v", got)
+	}
+	if got := snapshot.LogLevels["panic"]; got != (output.Counts{Messages: 1, Bytes: 5}) {
+		t.Errorf("Snapshot() panic = %+v, want 1 message", got)
+	}
+	if got := snapshot.Channels["log"]; got != (output.Counts{Messages: 2, Bytes: 9}) {
+		t.Errorf("Snapshot() log = %+v, want 2 messages", got)
+	}
+}
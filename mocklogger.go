@@ -0,0 +1,187 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type (
+	// LogEntry is a single call into a Logger: the level, the message, and the
+	// fields.
+	LogEntry struct {
+		Level  Level
+		Msg    string
+		Fields map[string]any
+	}
+
+	// MockLogger is a Logger intended for testing code that wraps a Logger; it
+	// records every call made into it and compares those calls against a list
+	// of expected calls.
+	//
+	// Unlike RecordingLogger, a MockLogger can be told to behave like a
+	// production logger: PanicOnPanic makes it call panic() after recording a
+	// panic log message, and OnFatal supplies a function to call (instead of
+	// exiting the program) after recording a fatal log message. A call at a
+	// level for which no call was expected is reported to the TestingReporter
+	// as soon as it is made.
+	MockLogger struct {
+		t            TestingReporter
+		lock         sync.Mutex
+		expected     []LogEntry
+		actual       []LogEntry
+		panicOnPanic bool
+		fatalHook    func()
+	}
+)
+
+// String formats the LogEntry the same way that RecordingLogger formats a log
+// message.
+func (le LogEntry) String() string {
+	return formatLogEntry(le.Level.String(), le.Msg, le.Fields)
+}
+
+func (le LogEntry) matches(other LogEntry) bool {
+	if le.Level != other.Level || le.Msg != other.Msg {
+		return false
+	}
+	if len(le.Fields) == 0 && len(other.Fields) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(le.Fields, other.Fields)
+}
+
+// NewMockLogger returns a MockLogger that reports unexpected calls to t.
+func NewMockLogger(t TestingReporter) *MockLogger {
+	return &MockLogger{t: t}
+}
+
+// Expect adds a call to the list of expected calls; calls are expected in the
+// order in which they are added. It returns the MockLogger so that calls can be
+// chained.
+func (ml *MockLogger) Expect(l Level, msg string, fields map[string]any) *MockLogger {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	ml.expected = append(ml.expected, LogEntry{Level: l, Msg: msg, Fields: fields})
+	return ml
+}
+
+// PanicOnPanic makes the MockLogger call panic(), with the log message as its
+// value, after recording a panic log message. It returns the MockLogger so that
+// calls can be chained.
+func (ml *MockLogger) PanicOnPanic() *MockLogger {
+	ml.panicOnPanic = true
+	return ml
+}
+
+// OnFatal makes the MockLogger call hook after recording a fatal log message,
+// standing in for the program exit that a production logger would perform. It
+// returns the MockLogger so that calls can be chained.
+func (ml *MockLogger) OnFatal(hook func()) *MockLogger {
+	ml.fatalHook = hook
+	return ml
+}
+
+// Calls returns the calls made into the MockLogger so far.
+func (ml *MockLogger) Calls() []LogEntry {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	return append([]LogEntry(nil), ml.actual...)
+}
+
+// Trace records a trace log message.
+func (ml *MockLogger) Trace(msg string, fields map[string]any) {
+	ml.record(Trace, msg, fields)
+}
+
+// Debug records a debug log message.
+func (ml *MockLogger) Debug(msg string, fields map[string]any) {
+	ml.record(Debug, msg, fields)
+}
+
+// Info records an info log message.
+func (ml *MockLogger) Info(msg string, fields map[string]any) {
+	ml.record(Info, msg, fields)
+}
+
+// Warning records a warning log message.
+func (ml *MockLogger) Warning(msg string, fields map[string]any) {
+	ml.record(Warning, msg, fields)
+}
+
+// Error records an error log message.
+func (ml *MockLogger) Error(msg string, fields map[string]any) {
+	ml.record(Error, msg, fields)
+}
+
+// Panic records a panic log message, and then calls panic() if PanicOnPanic
+// has been called.
+func (ml *MockLogger) Panic(msg string, fields map[string]any) {
+	ml.record(Panic, msg, fields)
+	if ml.panicOnPanic {
+		panic(msg)
+	}
+}
+
+// Fatal records a fatal log message, and then calls the hook set by OnFatal,
+// if any. It never terminates the program.
+func (ml *MockLogger) Fatal(msg string, fields map[string]any) {
+	ml.record(Fatal, msg, fields)
+	if ml.fatalHook != nil {
+		ml.fatalHook()
+	}
+}
+
+func (ml *MockLogger) record(l Level, msg string, fields map[string]any) {
+	ml.lock.Lock()
+	entry := LogEntry{Level: l, Msg: msg, Fields: fields}
+	ml.actual = append(ml.actual, entry)
+	expectedLevel := false
+	for _, e := range ml.expected {
+		if e.Level == l {
+			expectedLevel = true
+			break
+		}
+	}
+	ml.lock.Unlock()
+	if !expectedLevel {
+		ml.t.Errorf("MockLogger: unexpected %s call: %s", l, entry)
+	}
+}
+
+// Verify compares the calls made into the MockLogger against the expected
+// calls; it returns a readable report of expected versus actual calls, and
+// whether they matched.
+func (ml *MockLogger) Verify() (report string, verified bool) {
+	ml.lock.Lock()
+	defer ml.lock.Unlock()
+	verified = true
+	lines := []string{fmt.Sprintf("expected %d log call(s), got %d", len(ml.expected), len(ml.actual))}
+	for i := 0; i < max(len(ml.expected), len(ml.actual)); i++ {
+		switch {
+		case i >= len(ml.actual):
+			lines = append(lines, fmt.Sprintf("missing  %d: expected %s", i+1, ml.expected[i]))
+			verified = false
+		case i >= len(ml.expected):
+			lines = append(lines, fmt.Sprintf("extra    %d: got      %s", i+1, ml.actual[i]))
+			verified = false
+		case ml.expected[i].matches(ml.actual[i]):
+			lines = append(lines, fmt.Sprintf("ok       %d: %s", i+1, ml.actual[i]))
+		default:
+			lines = append(lines,
+				fmt.Sprintf("mismatch %d: expected %s", i+1, ml.expected[i]),
+				fmt.Sprintf("           got      %s", ml.actual[i]))
+			verified = false
+		}
+	}
+	return strings.Join(lines, "\n"), verified
+}
+
+// AssertExpectations reports the result of Verify to the TestingReporter if the
+// calls made do not match the expected calls.
+func (ml *MockLogger) AssertExpectations() {
+	if report, verified := ml.Verify(); !verified {
+		ml.t.Errorf("MockLogger: %s", report)
+	}
+}
@@ -0,0 +1,124 @@
+package output_test
+
+import (
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestMockLogger_Verify(t *testing.T) {
+	tests := map[string]struct {
+		expect       []output.LogEntry
+		calls        []output.LogEntry
+		wantReport   string
+		wantVerified bool
+		wantErrors   []string
+	}{
+		"match": {
+			expect: []output.LogEntry{
+				{Level: output.Warning, Msg: "disk low", Fields: map[string]any{"free": 10}},
+				{Level: output.Info, Msg: "done"},
+			},
+			calls: []output.LogEntry{
+				{Level: output.Warning, Msg: "disk low", Fields: map[string]any{"free": 10}},
+				{Level: output.Info, Msg: "done", Fields: map[string]any{}},
+			},
+			wantReport: "expected 2 log call(s), got 2\n" +
+				"ok       1: level='warning' free='10' msg='disk low'\n" +
+				"ok       2: level='info'  msg='done'",
+			wantVerified: true,
+		},
+		"mismatch, missing": {
+			expect: []output.LogEntry{
+				{Level: output.Warning, Msg: "disk low", Fields: map[string]any{"free": 10}},
+				{Level: output.Info, Msg: "done"},
+			},
+			calls: []output.LogEntry{
+				{Level: output.Warning, Msg: "disk low", Fields: map[string]any{"free": 9}},
+			},
+			wantReport: "expected 2 log call(s), got 1\n" +
+				"mismatch 1: expected level='warning' free='10' msg='disk low'\n" +
+				"           got      level='warning' free='9' msg='disk low'\n" +
+				"missing  2: expected level='info'  msg='done'",
+		},
+		"extra at unexpected level": {
+			expect: []output.LogEntry{{Level: output.Info, Msg: "done"}},
+			calls: []output.LogEntry{
+				{Level: output.Info, Msg: "done"},
+				{Level: output.Debug, Msg: "chatter"},
+			},
+			wantReport: "expected 1 log call(s), got 2\n" +
+				"ok       1: level='info'  msg='done'\n" +
+				"extra    2: got      level='debug'  msg='chatter'",
+			wantErrors: []string{"MockLogger: unexpected debug call: level='debug'  msg='chatter'"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			vr := newVerificationReporter()
+			ml := output.NewMockLogger(vr)
+			for _, e := range tt.expect {
+				ml.Expect(e.Level, e.Msg, e.Fields)
+			}
+			o := output.NewCustomBus(nil, nil, ml)
+			for _, c := range tt.calls {
+				o.Log(c.Level, c.Msg, c.Fields)
+			}
+			if got := len(ml.Calls()); got != len(tt.calls) {
+				t.Errorf("MockLogger.Calls() got %d calls, want %d", got, len(tt.calls))
+			}
+			gotReport, gotVerified := ml.Verify()
+			if gotReport != tt.wantReport {
+				t.Errorf("MockLogger.Verify() report = %q, want %q", gotReport, tt.wantReport)
+			}
+			if gotVerified != tt.wantVerified {
+				t.Errorf("MockLogger.Verify() verified = %t, want %t", gotVerified, tt.wantVerified)
+			}
+			ml.AssertExpectations()
+			wantErrors := tt.wantErrors
+			if !tt.wantVerified {
+				wantErrors = append(wantErrors, "MockLogger: "+tt.wantReport)
+			}
+			if len(vr.buffer) != len(wantErrors) {
+				t.Fatalf("MockLogger reported %q, want %q", vr.buffer, wantErrors)
+			}
+			for i, s := range vr.buffer {
+				if s != wantErrors[i] {
+					t.Errorf("MockLogger reported %q, want %q", s, wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMockLogger_Panic(t *testing.T) {
+	ml := output.NewMockLogger(t).Expect(output.Panic, "boom", nil).PanicOnPanic()
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("MockLogger.Panic() recovered %v, want %q", r, "boom")
+		}
+		ml.AssertExpectations()
+	}()
+	ml.Panic("boom", nil)
+	t.Errorf("MockLogger.Panic() did not panic")
+}
+
+func TestMockLogger_Fatal(t *testing.T) {
+	exited := false
+	ml := output.NewMockLogger(t).
+		Expect(output.Fatal, "bye", nil).
+		Expect(output.Trace, "t", nil).
+		Expect(output.Error, "e", nil).
+		OnFatal(func() { exited = true })
+	ml.Fatal("bye", nil)
+	if !exited {
+		t.Errorf("MockLogger.Fatal() did not call the hook")
+	}
+	ml.Trace("t", nil)
+	ml.Error("e", nil)
+	ml.AssertExpectations()
+	plain := output.NewMockLogger(t).Expect(output.Fatal, "bye", nil).Expect(output.Panic, "boom", nil)
+	plain.Fatal("bye", nil)
+	plain.Panic("boom", nil)
+	plain.AssertExpectations()
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Normalizer transforms text before a Recorder compares it; a Recorder applies
+// its normalizers, in order, both to the output it recorded and to the
+// expected output it is asked to verify.
+type Normalizer func(string) string
+
+var (
+	ansiEscapes = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)")
+	timestamps  = regexp.MustCompile(
+		`\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	trailingWhitespace = regexp.MustCompile(`(?m)[ \t]+$`)
+)
+
+// WithNormalizers replaces the Recorder's normalization pipeline, which, by
+// default, consists of ReplaceNBSPs; omit ReplaceNBSPs to make a test sensitive
+// to non-breaking spaces. It returns the Recorder so that calls can be chained
+// onto NewRecorder().
+func (r *Recorder) WithNormalizers(normalizers ...Normalizer) *Recorder {
+	r.normalizers = normalizers
+	return r
+}
+
+func (r *Recorder) normalize(s string) string {
+	for _, n := range r.normalizers {
+		s = n(s)
+	}
+	return s
+}
+
+// ReplaceNBSPs replaces non-breaking spaces with ordinary spaces.
+func ReplaceNBSPs(s string) string {
+	return strings.ReplaceAll(s, "\u00a0", " ")
+}
+
+// StripANSI removes ANSI escape sequences, such as color codes and cursor
+// movement, and operating system commands, such as hyperlinks.
+func StripANSI(s string) string {
+	return ansiEscapes.ReplaceAllString(s, "")
+}
+
+// NormalizeCRLF replaces CRLF line endings with LF line endings.
+func NormalizeCRLF(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// TrimTrailingWhitespace removes spaces and tabs from the end of each line.
+func TrimTrailingWhitespace(s string) string {
+	return trailingWhitespace.ReplaceAllString(s, "")
+}
+
+// ReplacePath returns a Normalizer that replaces occurrences of path, in both
+// its native and its slash-separated forms, with replacement.
+func ReplacePath(path, replacement string) Normalizer {
+	return func(s string) string {
+		if path == "" {
+			return s
+		}
+		s = strings.ReplaceAll(s, path, replacement)
+		return strings.ReplaceAll(s, filepath.ToSlash(path), replacement)
+	}
+}
+
+// ReplaceTempDir returns a Normalizer that replaces the system temporary
+// directory (as returned by os.TempDir(), which is where testing.T.TempDir()
+// creates its directories) with replacement.
+func ReplaceTempDir(replacement string) Normalizer {
+	return ReplacePath(os.TempDir(), replacement)
+}
+
+// RedactTimestamps returns a Normalizer that replaces timestamps with
+// replacement; it recognizes RFC 3339 timestamps (with or without fractional
+// seconds and time zone offset, and with either a "T" or a space separating
+// the date from the time) and the timestamps written by the standard library's
+// log package.
+func RedactTimestamps(replacement string) Normalizer {
+	return func(s string) string {
+		return timestamps.ReplaceAllLiteralString(s, replacement)
+	}
+}
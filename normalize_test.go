@@ -0,0 +1,104 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestNormalizers(t *testing.T) {
+	tmp := os.TempDir()
+	tests := map[string]struct {
+		n    output.Normalizer
+		in   string
+		want string
+	}{
+		"nbsp": {
+			n:    output.ReplaceNBSPs,
+			in:   "a\u00a0b",
+			want: "a b",
+		},
+		"ansi": {
+			n:    output.StripANSI,
+			in:   "\x1b[1;31mred\x1b[0m \x1b]8;;https://example.com\x07link\x1b]8;;\x1b\\ \x1b[2K",
+			want: "red link ",
+		},
+		"crlf": {
+			n:    output.NormalizeCRLF,
+			in:   "a\r\nb\r\n",
+			want: "a\nb\n",
+		},
+		"trailing whitespace": {
+			n:    output.TrimTrailingWhitespace,
+			in:   "a  \nb\t\n  c",
+			want: "a\nb\n  c",
+		},
+		"path": {
+			n:    output.ReplacePath(filepath.Join("x", "y"), "<dir>"),
+			in:   "x/y/z",
+			want: "<dir>/z",
+		},
+		"empty path": {
+			n:    output.ReplacePath("", "<dir>"),
+			in:   "x/y/z",
+			want: "x/y/z",
+		},
+		"temp dir": {
+			n:    output.ReplaceTempDir("$TMP"),
+			in:   "wrote " + filepath.Join(tmp, "file.txt"),
+			want: "wrote " + filepath.Join("$TMP", "file.txt"),
+		},
+		"timestamps": {
+			n: output.RedactTimestamps("<ts>"),
+			in: "ts=2026-10-18T09:15:02Z a=2026-10-18T09:15:02.123456-05:00 " +
+				"b=2026-10-18 09:15:02 c=2026/10/18 09:15:02",
+			want: "ts=<ts> a=<ts> b=<ts> c=<ts>",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.n(tt.in); got != tt.want {
+				t.Errorf("Normalizer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorder_WithNormalizers(t *testing.T) {
+	tests := map[string]struct {
+		r *output.Recorder
+		output.WantedRecording
+		wantVerified bool
+	}{
+		"default replaces nbsp": {
+			r:               output.NewRecorder(),
+			WantedRecording: output.WantedRecording{Console: "\x1b[1mbold\x1b[0m text  \r\n"},
+			wantVerified:    true,
+		},
+		"nbsp sensitive": {
+			r:               output.NewRecorder().WithNormalizers(),
+			WantedRecording: output.WantedRecording{Console: "\x1b[1mbold\x1b[0m text  \r\n"},
+			wantVerified:    false,
+		},
+		"pipeline": {
+			r: output.NewRecorder().WithNormalizers(
+				output.StripANSI,
+				output.ReplaceNBSPs,
+				output.NormalizeCRLF,
+				output.TrimTrailingWhitespace,
+			),
+			WantedRecording: output.WantedRecording{Console: "bold text\n"},
+			wantVerified:    true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.r.ConsolePrintf("\x1b[1mbold\x1b[0m\u00a0text  \r\n")
+			if _, got := tt.r.Verify(tt.WantedRecording); got != tt.wantVerified {
+				t.Errorf("Recorder.Verify() = %t, want %t", got, tt.wantVerified)
+			}
+		})
+	}
+}
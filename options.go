@@ -0,0 +1,163 @@
+package output
+
+import (
+	"io"
+	"os"
+)
+
+type (
+	// Option configures the Bus returned by NewBus.
+	Option func(*busOptions)
+
+	busOptions struct {
+		consoleWriter io.Writer
+		errorWriter   io.Writer
+		logger        Logger
+		tab           uint8
+		consoleTTY    *bool
+		errorTTY      *bool
+		listBullet    string
+		logLevel      *Level
+		redaction     *Redaction
+		consolePrefix *LinePrefix
+		errorPrefix   *LinePrefix
+		interceptors  []Interceptor
+		metrics       *Metrics
+	}
+)
+
+// NewBus returns an implementation of Bus configured by the specified
+// Options. Without any Options, it writes console messages to stdout and error
+// messages to stderr, and discards log messages, as if by NilLogger. Options
+// are applied in order, so a later Option overrides an earlier one that sets
+// the same thing; the order of different Options does not matter.
+func NewBus(opts ...Option) Bus {
+	o := &busOptions{consoleWriter: os.Stdout, errorWriter: os.Stderr, logger: NilLogger{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	consoleWriter := o.consoleWriter
+	if o.consolePrefix != nil {
+		consoleWriter = NewPrefixWriter(consoleWriter, *o.consolePrefix)
+	}
+	errorWriter := o.errorWriter
+	if o.errorPrefix != nil {
+		errorWriter = NewPrefixWriter(errorWriter, *o.errorPrefix)
+	}
+	logger := o.logger
+	if o.redaction != nil {
+		logger = NewRedactingLogger(logger, o.redaction)
+	}
+	b := &bus{
+		consoleWriter:        consoleWriter,
+		errorWriter:          errorWriter,
+		logger:               logger,
+		performWrites:        true,
+		consoleTTY:           resolveTTY(o.consoleTTY, consoleWriter),
+		errorTTY:             resolveTTY(o.errorTTY, errorWriter),
+		tab:                  o.tab,
+		consoleListDecorator: newListDecorator(false, false),
+		errorListDecorator:   newListDecorator(false, false),
+		listBullet:           o.listBullet,
+		interceptors:         o.interceptors,
+		logLevel:             o.logLevel,
+	}
+	if o.metrics != nil {
+		b.subscribers.add(nil, o.metrics.observe)
+	}
+	return b
+}
+
+func resolveTTY(forced *bool, w io.Writer) bool {
+	if forced != nil {
+		return *forced
+	}
+	return isTTY(w)
+}
+
+// WithConsoleWriter sets the writer for console output; the default is
+// os.Stdout.
+func WithConsoleWriter(w io.Writer) Option {
+	return func(o *busOptions) {
+		o.consoleWriter = w
+	}
+}
+
+// WithErrorWriter sets the writer for error output; the default is os.Stderr.
+func WithErrorWriter(w io.Writer) Option {
+	return func(o *busOptions) {
+		o.errorWriter = w
+	}
+}
+
+// WithLogger sets the Logger; the default is NilLogger.
+func WithLogger(l Logger) Option {
+	return func(o *busOptions) {
+		o.logger = l
+	}
+}
+
+// WithTab sets the initial tab setting (number of spaces); the default is 0.
+func WithTab(t uint8) Option {
+	return func(o *busOptions) {
+		o.tab = t
+	}
+}
+
+// WithConsoleTTY forces the value returned by IsConsoleTTY, instead of
+// determining whether the console writer is a terminal.
+func WithConsoleTTY(tty bool) Option {
+	return func(o *busOptions) {
+		o.consoleTTY = &tty
+	}
+}
+
+// WithErrorTTY forces the value returned by IsErrorTTY, instead of determining
+// whether the error writer is a terminal.
+func WithErrorTTY(tty bool) Option {
+	return func(o *busOptions) {
+		o.errorTTY = &tty
+	}
+}
+
+// WithListBullet sets the bullet, which is followed by a space, that decorates
+// the items of non-numeric console and error lists; the default is "●".
+func WithListBullet(bullet string) Option {
+	return func(o *busOptions) {
+		o.listBullet = bullet
+	}
+}
+
+// WithLogLevel makes the Bus discard log messages less severe than the
+// specified level, without passing them to interceptors, subscribers, or the
+// Logger; by default, every log message is passed to the Logger. Discarded
+// warning and error log messages are still counted in the Bus's Tally.
+func WithLogLevel(l Level) Option {
+	return func(o *busOptions) {
+		o.logLevel = &l
+	}
+}
+
+// WithLogRedaction applies a Redaction to the fields of log messages before
+// they are passed to the Logger, as RedactingLogger does.
+func WithLogRedaction(r *Redaction) Option {
+	return func(o *busOptions) {
+		o.redaction = r
+	}
+}
+
+// WithConsolePrefix writes the specified LinePrefix at the start of each line
+// of console output, as PrefixWriter does.
+func WithConsolePrefix(p LinePrefix) Option {
+	return func(o *busOptions) {
+		o.consolePrefix = &p
+	}
+}
+
+// WithErrorPrefix writes the specified LinePrefix at the start of each line of
+// error output, as PrefixWriter does.
+func WithErrorPrefix(p LinePrefix) Option {
+	return func(o *busOptions) {
+		o.errorPrefix = &p
+	}
+}
@@ -0,0 +1,104 @@
+package output_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/majohn-r/output"
+)
+
+func TestNewBus(t *testing.T) {
+	if got, want := output.NewBus(), output.NewCustomBus(os.Stdout, os.Stderr, output.NilLogger{}); !reflect.DeepEqual(
+		got, want) {
+		t.Errorf("NewBus() = %v, want %v", got, want)
+	}
+	console := &bytes.Buffer{}
+	errorOutput := &bytes.Buffer{}
+	logged := &bytes.Buffer{}
+	redaction, _ := output.NewRedaction("password")
+	o := output.NewBus(
+		output.WithConsoleWriter(console),
+		output.WithErrorWriter(errorOutput),
+		output.WithLogger(output.NewLogfmtLogger(logged).WithClock(func() time.Time {
+			return time.Date(2026, 10, 18, 9, 15, 2, 0, time.UTC)
+		})),
+		output.WithTab(2),
+		output.WithConsoleTTY(true),
+		output.WithListBullet("-"),
+		output.WithLogLevel(output.Info),
+		output.WithLogRedaction(redaction),
+		output.WithConsolePrefix(output.LinePrefix{Tag: "out"}),
+		output.WithErrorPrefix(output.LinePrefix{Tag: "err"}),
+	)
+	if !o.IsConsoleTTY() || o.IsErrorTTY() {
+		t.Errorf("NewBus() TTY = %t, %t, want true, false", o.IsConsoleTTY(), o.IsErrorTTY())
+	}
+	if got := o.Tab(); got != 2 {
+		t.Errorf("NewBus() Tab() = %d, want 2", got)
+	}
+	o.BeginConsoleList(false)
+	o.ConsolePrintln("item")
+	o.EndConsoleList()
+	o.BeginErrorList(false)
+	o.ErrorPrintln("problem")
+	o.BeginErrorList(true)
+	o.ErrorPrintln("first")
+	o.Log(output.Debug, "discarded", nil)
+	o.Log(output.Info, "login", map[string]any{"password": "hunter2"})
+	if got, want := console.String(), "[out]   - item\n"; got != want {
+		t.Errorf("NewBus() console output = %q, want %q", got, want)
+	}
+	if got, want := errorOutput.String(), "[err] - problem\n[err]  1. first\n"; got != want {
+		t.Errorf("NewBus() error output = %q, want %q", got, want)
+	}
+	if got, want := logged.String(), "ts=2026-10-18T09:15:02Z level=info msg=login password=[REDACTED]\n"; got != want {
+		t.Errorf("NewBus() log output = %q, want %q", got, want)
+	}
+}
+
+func TestNewBus_optionOrder(t *testing.T) {
+	console := &bytes.Buffer{}
+	o := output.NewBus(output.WithConsoleTTY(true), output.WithConsoleWriter(console), output.WithTab(4),
+		output.WithTab(1), output.WithErrorTTY(true), output.WithErrorTTY(false))
+	if !o.IsConsoleTTY() || o.IsErrorTTY() {
+		t.Errorf("NewBus() TTY = %t, %t, want true, false", o.IsConsoleTTY(), o.IsErrorTTY())
+	}
+	o.ConsolePrintln("x")
+	if got := console.String(); got != " x\n" {
+		t.Errorf("NewBus() console output = %q, want %q", got, " x\n")
+	}
+}
+
+func TestWithLogLevel(t *testing.T) {
+	errorOutput := &bytes.Buffer{}
+	logger := output.NewMockLogger(t).Expect(output.Error, "broken", nil)
+	o := output.NewBus(output.WithErrorWriter(errorOutput), output.WithLogger(panickingErrorLogger{logger}),
+		output.WithLogLevel(output.Warning))
+	o.Log(output.Info, "discarded", nil)
+	o.Log(output.Level(99), "invalid", nil)
+	if !strings.Contains(errorOutput.String(), "invalid level value 99") {
+		t.Errorf("WithLogLevel() hid an invalid level: %q", errorOutput.String())
+	}
+	defer func() {
+		if got := recover(); got != "logger bug" {
+			t.Errorf("WithLogLevel() swallowed the Logger's panic: %v", got)
+		}
+		logger.AssertExpectations()
+	}()
+	o.Log(output.Error, "broken", nil)
+}
+
+// panickingErrorLogger panics after logging an error log message, as a buggy
+// Logger might
+type panickingErrorLogger struct {
+	*output.MockLogger
+}
+
+func (pel panickingErrorLogger) Error(msg string, fields map[string]any) {
+	pel.MockLogger.Error(msg, fields)
+	panic("logger bug")
+}
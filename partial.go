@@ -0,0 +1,165 @@
+package output
+
+import "io"
+
+// partialBus is a Bus whose functions each come from either an override or a
+// fallback Bus
+type partialBus struct {
+	fallback             Bus
+	log                  func(Level, string, map[string]any)
+	consolePrintf        func(string, ...any)
+	consolePrintln       func(string)
+	consoleWriter        func() io.Writer
+	isConsoleTTY         func() bool
+	errorPrintf          func(string, ...any)
+	errorPrintln         func(string)
+	errorWriter          func() io.Writer
+	isErrorTTY           func() bool
+	tab                  func() uint8
+	incrementTab         func(uint8)
+	decrementTab         func(uint8)
+	beginConsoleList     func(bool)
+	endConsoleList       func()
+	consoleListDecorator func() *ListDecorator
+	beginErrorList       func(bool)
+	endErrorList         func()
+	errorListDecorator   func() *ListDecorator
+}
+
+// NewPartialBus returns a Bus that calls override's implementation of each Bus
+// function that override implements, and fallback's implementation of every
+// other Bus function; override may implement any subset of the Bus functions,
+// e.g., only ErrorPrintf and ErrorPrintln, or only the functions of LogSink.
+// This lets middleware override only what it needs to.
+//
+// The returned Bus has an Unwrap() Bus function that returns fallback, so that
+// TallyOf and Flush see through it.
+func NewPartialBus(fallback Bus, override any) Bus {
+	return &partialBus{
+		fallback: fallback,
+		log: choose[interface {
+			Log(Level, string, map[string]any)
+		}](override, fallback).Log,
+		consolePrintf:        choose[interface{ ConsolePrintf(string, ...any) }](override, fallback).ConsolePrintf,
+		consolePrintln:       choose[interface{ ConsolePrintln(string) }](override, fallback).ConsolePrintln,
+		consoleWriter:        choose[interface{ ConsoleWriter() io.Writer }](override, fallback).ConsoleWriter,
+		isConsoleTTY:         choose[interface{ IsConsoleTTY() bool }](override, fallback).IsConsoleTTY,
+		errorPrintf:          choose[interface{ ErrorPrintf(string, ...any) }](override, fallback).ErrorPrintf,
+		errorPrintln:         choose[interface{ ErrorPrintln(string) }](override, fallback).ErrorPrintln,
+		errorWriter:          choose[interface{ ErrorWriter() io.Writer }](override, fallback).ErrorWriter,
+		isErrorTTY:           choose[interface{ IsErrorTTY() bool }](override, fallback).IsErrorTTY,
+		tab:                  choose[interface{ Tab() uint8 }](override, fallback).Tab,
+		incrementTab:         choose[interface{ IncrementTab(uint8) }](override, fallback).IncrementTab,
+		decrementTab:         choose[interface{ DecrementTab(uint8) }](override, fallback).DecrementTab,
+		beginConsoleList:     choose[interface{ BeginConsoleList(bool) }](override, fallback).BeginConsoleList,
+		endConsoleList:       choose[interface{ EndConsoleList() }](override, fallback).EndConsoleList,
+		consoleListDecorator: choose[interface{ ConsoleListDecorator() *ListDecorator }](override, fallback).ConsoleListDecorator,
+		beginErrorList:       choose[interface{ BeginErrorList(bool) }](override, fallback).BeginErrorList,
+		endErrorList:         choose[interface{ EndErrorList() }](override, fallback).EndErrorList,
+		errorListDecorator:   choose[interface{ ErrorListDecorator() *ListDecorator }](override, fallback).ErrorListDecorator,
+	}
+}
+
+// choose returns override if it implements I, and fallback otherwise
+func choose[I any](override any, fallback I) I {
+	if implementation, ok := override.(I); ok {
+		return implementation
+	}
+	return fallback
+}
+
+// Unwrap returns the fallback Bus.
+func (pb *partialBus) Unwrap() Bus {
+	return pb.fallback
+}
+
+// Log logs a message and map of fields at a specified log level.
+func (pb *partialBus) Log(l Level, msg string, fields map[string]any) {
+	pb.log(l, msg, fields)
+}
+
+// ConsolePrintf prints a message with arguments to the console channel
+func (pb *partialBus) ConsolePrintf(format string, args ...any) {
+	pb.consolePrintf(format, args...)
+}
+
+// ConsolePrintln prints a message to the console channel, terminated by a
+// newline
+func (pb *partialBus) ConsolePrintln(msg string) {
+	pb.consolePrintln(msg)
+}
+
+// ConsoleWriter returns a writer for console output.
+func (pb *partialBus) ConsoleWriter() io.Writer {
+	return pb.consoleWriter()
+}
+
+// IsConsoleTTY returns whether the console writer is a TTY
+func (pb *partialBus) IsConsoleTTY() bool {
+	return pb.isConsoleTTY()
+}
+
+// ErrorPrintf prints a message with arguments to the error channel
+func (pb *partialBus) ErrorPrintf(format string, args ...any) {
+	pb.errorPrintf(format, args...)
+}
+
+// ErrorPrintln prints a message to the error channel, terminated by a newline
+func (pb *partialBus) ErrorPrintln(msg string) {
+	pb.errorPrintln(msg)
+}
+
+// ErrorWriter returns a writer for error output.
+func (pb *partialBus) ErrorWriter() io.Writer {
+	return pb.errorWriter()
+}
+
+// IsErrorTTY returns whether the error writer is a TTY
+func (pb *partialBus) IsErrorTTY() bool {
+	return pb.isErrorTTY()
+}
+
+// Tab returns the current tab setting
+func (pb *partialBus) Tab() uint8 {
+	return pb.tab()
+}
+
+// IncrementTab increments the tab setting by the specified number of spaces
+func (pb *partialBus) IncrementTab(t uint8) {
+	pb.incrementTab(t)
+}
+
+// DecrementTab decrements the tab setting by the specified number of spaces
+func (pb *partialBus) DecrementTab(t uint8) {
+	pb.decrementTab(t)
+}
+
+// BeginConsoleList initiates console listing
+func (pb *partialBus) BeginConsoleList(numeric bool) {
+	pb.beginConsoleList(numeric)
+}
+
+// EndConsoleList terminates console listing
+func (pb *partialBus) EndConsoleList() {
+	pb.endConsoleList()
+}
+
+// ConsoleListDecorator makes the console list decorator available
+func (pb *partialBus) ConsoleListDecorator() *ListDecorator {
+	return pb.consoleListDecorator()
+}
+
+// BeginErrorList initiates error listing
+func (pb *partialBus) BeginErrorList(numeric bool) {
+	pb.beginErrorList(numeric)
+}
+
+// EndErrorList terminates error listing
+func (pb *partialBus) EndErrorList() {
+	pb.endErrorList()
+}
+
+// ErrorListDecorator makes the error list decorator available
+func (pb *partialBus) ErrorListDecorator() *ListDecorator {
+	return pb.errorListDecorator()
+}
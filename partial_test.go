@@ -0,0 +1,68 @@
+package output_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+// shoutingErrors overrides only the error channel's Println
+type shoutingErrors struct {
+	next output.ErrorPrinter
+}
+
+func (se shoutingErrors) ErrorPrintln(msg string) {
+	se.next.ErrorPrintln(msg + "!")
+}
+
+// logCollector overrides only the LogSink functions
+type logCollector struct {
+	entries []string
+}
+
+func (lc *logCollector) Log(l output.Level, msg string, _ map[string]any) {
+	lc.entries = append(lc.entries, fmt.Sprintf("%s: %s", l, msg))
+}
+
+func TestNewPartialBus(t *testing.T) {
+	o := output.NewRecorder()
+	collector := &logCollector{}
+	var p output.Bus = output.NewPartialBus(o, shoutingErrors{next: o})
+	p = output.NewPartialBus(p, collector)
+	exerciseBus(p)
+	p.ErrorPrintln("careful")
+	if got, want := fmt.Sprint(collector.entries), "[warning: careful]"; got != want {
+		t.Errorf("NewPartialBus() logged %s, want %s", got, want)
+	}
+	if got := o.LogOutput(); got != "" {
+		t.Errorf("NewPartialBus() passed log messages to the fallback: %q", got)
+	}
+	o.Report(t, "NewPartialBus()", output.WantedRecording{
+		Console: "   1. list has 2 items\n   2. second\nraw\n",
+		Error:   "● bad <thing>!\nerror: oops\ncareful!\n",
+	})
+	if errorCount, _ := output.TallyOf(p).Counts(); errorCount != 3 {
+		t.Errorf("TallyOf() counted %d errors, want 3", errorCount)
+	}
+	if got := p.IsConsoleTTY(); got {
+		t.Errorf("IsConsoleTTY() = true")
+	}
+	if p.ConsoleWriter() != o.ConsoleWriter() || p.ErrorWriter() != o.ErrorWriter() {
+		t.Errorf("NewPartialBus() did not fall back to the Recorder's writers")
+	}
+}
+
+func TestNewPartialBus_everything(t *testing.T) {
+	// an override that implements all of Bus replaces the fallback entirely
+	console := &bytes.Buffer{}
+	override := output.NewCustomBus(console, output.NilWriter{}, output.NilLogger{})
+	fallback := output.NewRecorder()
+	p := output.NewPartialBus(fallback, override)
+	exerciseBus(p)
+	fallback.Report(t, "NewPartialBus()", output.WantedRecording{})
+	if console.Len() == 0 {
+		t.Errorf("NewPartialBus() did not use the override")
+	}
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// LinePrefix specifies the prefix that a PrefixWriter writes at the start
+	// of each line; the prefix consists of whichever of these elements are
+	// specified, in this order, separated by spaces and followed by a space:
+	//
+	//	2026/10/18 09:15:02 +1.5s myprog [ERROR] the rest of the line
+	LinePrefix struct {
+		// TimeFormat, if not empty, is the layout (as used by time.Time's
+		// Format function) of the time at which the line is written
+		TimeFormat string
+		// Elapsed specifies whether to write the time elapsed since the
+		// PrefixWriter was created, rounded to the millisecond
+		Elapsed bool
+		// ProgramName, if not empty, is written as is
+		ProgramName string
+		// Tag, if not empty, is written in brackets, e.g., "[ERROR]"
+		Tag string
+		// Clock, if not nil, is used to determine the time; this is primarily
+		// of use to make tests deterministic
+		Clock Clock
+	}
+
+	// PrefixWriter is an io.Writer that writes a LinePrefix at the start of
+	// each line written to another io.Writer. Used as a Bus's console or error
+	// writer, it prefixes lines after the Bus has applied tab and list
+	// decoration, so alignment is unaffected.
+	PrefixWriter struct {
+		lock        sync.Mutex
+		writer      io.Writer
+		prefix      LinePrefix
+		start       time.Time
+		atLineStart bool
+	}
+)
+
+// NewPrefixWriter returns a PrefixWriter that writes to w; elapsed times are
+// measured from the time NewPrefixWriter is called.
+func NewPrefixWriter(w io.Writer, p LinePrefix) *PrefixWriter {
+	return &PrefixWriter{writer: w, prefix: p, start: p.Clock.now(), atLineStart: true}
+}
+
+// Unwrap returns the io.Writer that the PrefixWriter writes to.
+func (pw *PrefixWriter) Unwrap() io.Writer {
+	return pw.writer
+}
+
+// Write writes p, inserting the prefix at the start of each line; it returns
+// len(p) if the write succeeds.
+func (pw *PrefixWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	pw.lock.Lock()
+	defer pw.lock.Unlock()
+	prefix := pw.prefix.format(pw.start)
+	buffer := &bytes.Buffer{}
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if pw.atLineStart {
+			buffer.WriteString(prefix)
+		}
+		buffer.Write(line)
+		pw.atLineStart = line[len(line)-1] == '\n'
+	}
+	if _, err := pw.writer.Write(buffer.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (p LinePrefix) format(start time.Time) string {
+	now := p.Clock.now()
+	var parts []string
+	if p.TimeFormat != "" {
+		parts = append(parts, now.Format(p.TimeFormat))
+	}
+	if p.Elapsed {
+		parts = append(parts, "+"+now.Sub(start).Round(time.Millisecond).String())
+	}
+	if p.ProgramName != "" {
+		parts = append(parts, p.ProgramName)
+	}
+	if p.Tag != "" {
+		parts = append(parts, "["+p.Tag+"]")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, " ") + " "
+}
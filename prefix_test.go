@@ -0,0 +1,117 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/majohn-r/output"
+)
+
+type steppingClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+// tick returns the current time, and then advances it
+func (sc *steppingClock) tick() time.Time {
+	t := sc.now
+	sc.now = sc.now.Add(sc.step)
+	return t
+}
+
+func newSteppingClock() *steppingClock {
+	return &steppingClock{now: time.Date(2026, 10, 18, 9, 15, 2, 0, time.UTC), step: 1500 * time.Millisecond}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	tests := map[string]struct {
+		prefix output.LinePrefix
+		writes []string
+		want   string
+	}{
+		"no prefix": {
+			writes: []string{"a\nb\n"},
+			want:   "a\nb\n",
+		},
+		"everything": {
+			prefix: output.LinePrefix{
+				TimeFormat:  "2006/01/02 15:04:05",
+				Elapsed:     true,
+				ProgramName: "myprog",
+				Tag:         "ERROR",
+			},
+			writes: []string{"first\nsec", "", "ond\n\nthird"},
+			// the prefix is written when a line is started
+			want: "2026/10/18 09:15:03 +1.5s myprog [ERROR] first\n" +
+				"2026/10/18 09:15:03 +1.5s myprog [ERROR] second\n" +
+				"2026/10/18 09:15:05 +3s myprog [ERROR] \n" +
+				"2026/10/18 09:15:05 +3s myprog [ERROR] third",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.prefix.Clock = newSteppingClock().tick
+			w := &bytes.Buffer{}
+			pw := output.NewPrefixWriter(w, tt.prefix)
+			for _, s := range tt.writes {
+				if n, err := fmt.Fprint(pw, s); n != len(s) || err != nil {
+					t.Errorf("PrefixWriter.Write() = %d, %v", n, err)
+				}
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("PrefixWriter wrote %q, want %q", got, tt.want)
+			}
+			if pw.Unwrap() != w {
+				t.Errorf("PrefixWriter.Unwrap() did not return the wrapped writer")
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("closed")
+}
+
+func TestPrefixWriter_Error(t *testing.T) {
+	if n, err := output.NewPrefixWriter(failingWriter{}, output.LinePrefix{}).Write([]byte("x")); n != 0 || err == nil {
+		t.Errorf("PrefixWriter.Write() = %d, %v", n, err)
+	}
+}
+
+func TestPrefixWriter_Bus(t *testing.T) {
+	w := &bytes.Buffer{}
+	o := output.NewCustomBus(output.NewPrefixWriter(w, output.LinePrefix{ProgramName: "myprog"}), os.Stderr, nil)
+	o.IncrementTab(2)
+	o.BeginConsoleList(false)
+	o.ConsolePrintln("item")
+	if got, want := w.String(), "myprog   ● item\n"; got != want {
+		t.Errorf("Bus with PrefixWriter wrote %q, want %q", got, want)
+	}
+	if got, want := o.IsErrorTTY(), output.NewCustomBus(nil, os.Stderr, nil).IsErrorTTY(); got != want {
+		t.Errorf("Bus with PrefixWriter IsErrorTTY() = %t, want %t", got, want)
+	}
+}
+
+func TestRecorder_WithPrefix(t *testing.T) {
+	clock := newSteppingClock()
+	o := output.NewRecorder().
+		WithConsolePrefix(output.LinePrefix{Tag: "INFO"}).
+		WithErrorPrefix(output.LinePrefix{TimeFormat: time.TimeOnly, Clock: clock.tick})
+	o.IncrementTab(2)
+	o.BeginConsoleList(true)
+	o.ConsolePrintf("one\n")
+	o.ConsolePrintln("two")
+	o.ErrorPrintln("oops")
+	_, _ = fmt.Fprintln(o.ErrorWriter(), "raw")
+	_, _ = fmt.Fprintln(o.ConsoleWriter(), "raw")
+	o.Report(t, "Recorder.WithPrefix()", output.WantedRecording{
+		Console: "[INFO]    1. one\n[INFO]    2. two\n[INFO] raw\n",
+		Error:   "09:15:03 oops\n09:15:05 raw\n",
+	})
+}
@@ -23,6 +23,45 @@ type (
 		tab                  uint8
 		consoleListDecorator *ListDecorator
 		errorListDecorator   *ListDecorator
+		consoleTerminal      *terminalSize
+		errorTerminal        *terminalSize
+		checkpoint           recordingOffsets
+		normalizers          []Normalizer
+		consolePrefixer      *PrefixWriter
+		errorPrefixer        *PrefixWriter
+		interceptors         []Interceptor
+		subscribers          subscribers
+		tally                Tally
+	}
+
+	// recordingOffsets marks how much console, error, and log output had been
+	// recorded when a checkpoint was taken
+	recordingOffsets struct {
+		console int
+		error   int
+		log     int
+	}
+
+	// TerminalSizer is implemented by Bus implementations that know the
+	// dimensions of the terminals behind their console and error writers; code
+	// that adapts its output to the terminal size can type-assert a Bus to
+	// TerminalSizer. The Buses returned by NewBus, NewDefaultBus,
+	// NewCustomBus, and NewNilBus implement it, as does *Recorder; wrappers,
+	// such as the Buses returned by NewCallerBus, NewPartialBus, and
+	// FromContext, and CapturingBus, do not, so use TerminalSizeOf rather than
+	// a type assertion.
+	TerminalSizer interface {
+		// ConsoleTerminalSize returns the width and height of the console
+		// terminal; ok is false if the console writer is not a TTY
+		ConsoleTerminalSize() (width, height int, ok bool)
+		// ErrorTerminalSize returns the width and height of the error
+		// terminal; ok is false if the error writer is not a TTY
+		ErrorTerminalSize() (width, height int, ok bool)
+	}
+
+	terminalSize struct {
+		width  int
+		height int
 	}
 
 	// WantedRecording is intended to be used in unit tests as part of the test
@@ -53,11 +92,14 @@ type (
 	// will probably call panic in processing a panic log, and will probably
 	// exit the program on a fatal log. RecordingLogger does neither of those.
 	RecordingLogger struct {
-		writer *bytes.Buffer
+		writer    *bytes.Buffer
+		redaction *Redaction
 	}
 )
 
-// NewRecorder returns a recording implementation of Bus.
+// NewRecorder returns a recording implementation of Bus. Its normalization
+// pipeline replaces non-breaking spaces with ordinary spaces; see
+// WithNormalizers.
 func NewRecorder() *Recorder {
 	return &Recorder{
 		consoleWriter:        &bytes.Buffer{},
@@ -66,64 +108,82 @@ func NewRecorder() *Recorder {
 		tab:                  0,
 		consoleListDecorator: newListDecorator(false, false),
 		errorListDecorator:   newListDecorator(false, false),
+		normalizers:          []Normalizer{ReplaceNBSPs},
+		tally:                Tally{retain: -1},
 	}
 }
 
 // Log records a message and map of fields at a specified log level.
 func (r *Recorder) Log(l Level, msg string, fields map[string]any) {
-	switch l {
-	case Trace:
-		r.logger.Trace(msg, fields)
-	case Debug:
-		r.logger.Debug(msg, fields)
-	case Info:
-		r.logger.Info(msg, fields)
-	case Warning:
-		r.logger.Warning(msg, fields)
-	case Error:
-		r.logger.Error(msg, fields)
-	case Panic:
-		r.logger.Panic(msg, fields)
-	case Fatal:
-		r.logger.Fatal(msg, fields)
+	r.tally.countLog(l, msg, fields)
+	r.emit(Event{Channel: LogChannel, Text: msg, Level: l, Fields: fields})
+}
+
+// deliver records an Event that has passed through the interceptors
+func (r *Recorder) deliver(e Event) {
+	switch e.Channel {
+	case ConsoleChannel:
+		_, _ = io.WriteString(r.consoleOutput(), e.Text)
+	case ErrorChannel:
+		_, _ = io.WriteString(r.errorOutput(), e.Text)
+	case LogChannel:
+		if e.Level <= Panic {
+			// the Logger may panic or exit the program, so notify first
+			r.subscribers.notify(e)
+			logAt(r.logger, e.Level, e.Text, e.Fields)
+			return
+		}
+		if !logAt(r.logger, e.Level, e.Text, e.Fields) {
+			r.ErrorPrintf(
+				"Programming error: call to Recorder.Log() with invalid level value %d; message: '%s', args: '%v'.\n",
+				e.Level,
+				e.Text,
+				e.Fields,
+			)
+			return
+		}
 	default:
-		r.ErrorPrintf(
-			"Programming error: call to Recorder.Log() with invalid level value %d; message: '%s', args: '%v'.\n",
-			l,
-			msg,
-			fields,
-		)
+		return
 	}
+	r.subscribers.notify(e)
 }
 
-// ConsoleWriter returns the internal console writer.
+// ConsoleWriter returns the internal console writer; if a console prefix has
+// been set, the writer applies it.
 func (r *Recorder) ConsoleWriter() io.Writer {
-	return r.consoleWriter
+	return r.consoleOutput()
 }
 
-// ErrorWriter returns the internal error writer.
+// ErrorWriter returns the internal error writer; if an error prefix has been
+// set, the writer applies it.
 func (r *Recorder) ErrorWriter() io.Writer {
-	return r.errorWriter
+	return r.errorOutput()
 }
 
 // ErrorPrintln prints a message to the error channel, terminated by a newline
 func (r *Recorder) ErrorPrintln(msg string) {
-	doPrintln(r.errorWriter, r.errorListDecorator, msg)
+	r.tally.countErrorOutput(msg)
+	r.emit(Event{Channel: ErrorChannel, Text: doSprintln(r.errorListDecorator, msg)})
 }
 
 // ErrorPrintf prints a message with arguments to the error channel
 func (r *Recorder) ErrorPrintf(format string, args ...any) {
-	doPrintf(r.errorWriter, r.errorListDecorator, format, args...)
+	msg := fmt.Sprintf(format, args...)
+	r.tally.countErrorOutput(msg)
+	r.emit(Event{Channel: ErrorChannel, Text: decorated(r.errorListDecorator, msg)})
 }
 
 // ConsolePrintln prints a message to the error channel, terminated by a newline
 func (r *Recorder) ConsolePrintln(msg string) {
-	writeTabbedContent(r.consoleWriter, r.tab, doSprintln(r.consoleListDecorator, msg))
+	r.emit(Event{Channel: ConsoleChannel, Text: tabbedContent(r.tab, doSprintln(r.consoleListDecorator, msg))})
 }
 
 // ConsolePrintf prints a message with arguments to the error channel
 func (r *Recorder) ConsolePrintf(format string, args ...any) {
-	writeTabbedContent(r.consoleWriter, r.tab, doSprintf(r.consoleListDecorator, format, args...))
+	r.emit(Event{
+		Channel: ConsoleChannel,
+		Text:    tabbedContent(r.tab, doSprintf(r.consoleListDecorator, format, args...)),
+	})
 }
 
 // IncrementTab increments the tab setting by the specified number of spaces
@@ -173,50 +233,202 @@ func (r *Recorder) ErrorListDecorator() *ListDecorator {
 
 // ConsoleOutput returns the data written as console output.
 func (r *Recorder) ConsoleOutput() string {
-	return cleanseNBSPs(r.consoleWriter.String())
+	return r.normalize(r.consoleWriter.String())
 }
 
 // ErrorOutput returns the data written as error output.
 func (r *Recorder) ErrorOutput() string {
-	return cleanseNBSPs(r.errorWriter.String())
+	return r.normalize(r.errorWriter.String())
 }
 
 // LogOutput returns the data written to a log.
 func (r *Recorder) LogOutput() string {
-	return cleanseNBSPs(r.logger.writer.String())
+	return r.normalize(r.logger.writer.String())
+}
+
+// SimulateConsoleTTY makes the Recorder report that its console writer is a
+// TTY with the specified width and height; it returns the Recorder so that
+// calls can be chained onto NewRecorder().
+func (r *Recorder) SimulateConsoleTTY(width, height int) *Recorder {
+	r.consoleTerminal = &terminalSize{width: width, height: height}
+	return r
+}
+
+// SimulateErrorTTY makes the Recorder report that its error writer is a TTY
+// with the specified width and height; it returns the Recorder so that calls
+// can be chained onto NewRecorder().
+func (r *Recorder) SimulateErrorTTY(width, height int) *Recorder {
+	r.errorTerminal = &terminalSize{width: width, height: height}
+	return r
 }
 
-// IsConsoleTTY returns whether the console writer is a TTY
+// WithConsolePrefix makes the Recorder write the specified LinePrefix at the
+// start of each line of console output; supply a LinePrefix with a Clock to
+// keep time-based prefixes deterministic. It returns the Recorder so that calls
+// can be chained onto NewRecorder().
+func (r *Recorder) WithConsolePrefix(p LinePrefix) *Recorder {
+	r.consolePrefixer = NewPrefixWriter(r.consoleWriter, p)
+	return r
+}
+
+// WithErrorPrefix makes the Recorder write the specified LinePrefix at the
+// start of each line of error output; supply a LinePrefix with a Clock to keep
+// time-based prefixes deterministic. It returns the Recorder so that calls can
+// be chained onto NewRecorder().
+func (r *Recorder) WithErrorPrefix(p LinePrefix) *Recorder {
+	r.errorPrefixer = NewPrefixWriter(r.errorWriter, p)
+	return r
+}
+
+func (r *Recorder) consoleOutput() io.Writer {
+	if r.consolePrefixer != nil {
+		return r.consolePrefixer
+	}
+	return r.consoleWriter
+}
+
+func (r *Recorder) errorOutput() io.Writer {
+	if r.errorPrefixer != nil {
+		return r.errorPrefixer
+	}
+	return r.errorWriter
+}
+
+// WithRedaction makes the Recorder apply a Redaction to the fields of the log
+// messages it records; it returns the Recorder so that calls can be chained
+// onto NewRecorder().
+func (r *Recorder) WithRedaction(red *Redaction) *Recorder {
+	r.logger.WithRedaction(red)
+	return r
+}
+
+// Reset discards all recorded console, error, and log output, as well as any
+// checkpoint and everything counted by its Tally; the tab setting and the
+// console and error list decorators are preserved.
+func (r *Recorder) Reset() {
+	r.consoleWriter.Reset()
+	r.errorWriter.Reset()
+	r.logger.writer.Reset()
+	r.checkpoint = recordingOffsets{}
+	r.tally.Reset()
+}
+
+// Checkpoint marks the output recorded so far, so that subsequent calls to
+// the ...SinceCheckpoint functions only consider output recorded after this
+// call. Unlike Reset, Checkpoint discards nothing: ConsoleOutput, ErrorOutput,
+// and LogOutput continue to return everything recorded. The tab setting and the
+// console and error list decorators are unaffected.
+func (r *Recorder) Checkpoint() {
+	r.checkpoint = recordingOffsets{
+		console: r.consoleWriter.Len(),
+		error:   r.errorWriter.Len(),
+		log:     r.logger.writer.Len(),
+	}
+}
+
+// ConsoleOutputSinceCheckpoint returns the data written as console output
+// since the most recent checkpoint.
+func (r *Recorder) ConsoleOutputSinceCheckpoint() string {
+	return r.normalize(string(r.consoleWriter.Bytes()[r.checkpoint.console:]))
+}
+
+// ErrorOutputSinceCheckpoint returns the data written as error output since
+// the most recent checkpoint.
+func (r *Recorder) ErrorOutputSinceCheckpoint() string {
+	return r.normalize(string(r.errorWriter.Bytes()[r.checkpoint.error:]))
+}
+
+// LogOutputSinceCheckpoint returns the data written to a log since the most
+// recent checkpoint.
+func (r *Recorder) LogOutputSinceCheckpoint() string {
+	return r.normalize(string(r.logger.writer.Bytes()[r.checkpoint.log:]))
+}
+
+// IsConsoleTTY returns whether the console writer is a TTY; it is false unless
+// SimulateConsoleTTY has been called
 func (r *Recorder) IsConsoleTTY() bool {
-	return false
+	return r.consoleTerminal != nil
 }
 
-// IsErrorTTY returns whether the error writer is a TTY
+// IsErrorTTY returns whether the error writer is a TTY; it is false unless
+// SimulateErrorTTY has been called
 func (r *Recorder) IsErrorTTY() bool {
-	return false
+	return r.errorTerminal != nil
+}
+
+// TerminalSizeOf returns b as a TerminalSizer or, if b is a wrapper (a Bus
+// with an Unwrap() Bus function, such as CapturingBus), the Bus that it wraps;
+// it returns nil if there is no TerminalSizer to be found.
+func TerminalSizeOf(b Bus) TerminalSizer {
+	for b != nil {
+		if sizer, ok := b.(TerminalSizer); ok {
+			return sizer
+		}
+		wrapper, ok := b.(interface{ Unwrap() Bus })
+		if !ok {
+			break
+		}
+		b = wrapper.Unwrap()
+	}
+	return nil
 }
 
-func cleanseNBSPs(s string) string {
-	return strings.ReplaceAll(s, "\u00a0", " ")
+// ConsoleTerminalSize returns the simulated console terminal's width and
+// height; ok is false if the console writer is not a simulated TTY
+func (r *Recorder) ConsoleTerminalSize() (width, height int, ok bool) {
+	return r.consoleTerminal.dimensions()
+}
+
+// ErrorTerminalSize returns the simulated error terminal's width and height; ok
+// is false if the error writer is not a simulated TTY
+func (r *Recorder) ErrorTerminalSize() (width, height int, ok bool) {
+	return r.errorTerminal.dimensions()
+}
+
+func (ts *terminalSize) dimensions() (width, height int, ok bool) {
+	if ts == nil {
+		return 0, 0, false
+	}
+	return ts.width, ts.height, true
 }
 
 // Verify verifies the recorded output against the expected output and returns
 // any differences found.
 func (r *Recorder) Verify(w WantedRecording) (differences []string, verified bool) {
+	return r.verifyRecording(r.ConsoleOutput(), r.ErrorOutput(), r.LogOutput(), w)
+}
+
+// VerifySinceCheckpoint verifies the output recorded since the most recent
+// checkpoint against the expected output and returns any differences found.
+func (r *Recorder) VerifySinceCheckpoint(w WantedRecording) (differences []string, verified bool) {
+	return r.verifyRecording(
+		r.ConsoleOutputSinceCheckpoint(),
+		r.ErrorOutputSinceCheckpoint(),
+		r.LogOutputSinceCheckpoint(),
+		w,
+	)
+}
+
+// verifyRecording compares normalized recorded output against the normalized
+// expected output. The differences reported by cmp.Diff always have their NBSPs
+// replaced, regardless of the normalization pipeline, as go-cmp deliberately
+// mixes NBSPs into its output to discourage depending on its exact format.
+func (r *Recorder) verifyRecording(console, errors, log string, w WantedRecording) (
+	differences []string, verified bool) {
 	verified = true
-	wCon := cleanseNBSPs(w.Console)
-	if got := r.ConsoleOutput(); got != wCon {
-		differences = append(differences, fmt.Sprintf("console output = %s", cleanseNBSPs(cmp.Diff(wCon, got))))
+	wCon := r.normalize(w.Console)
+	if console != wCon {
+		differences = append(differences, fmt.Sprintf("console output = %s", ReplaceNBSPs(cmp.Diff(wCon, console))))
 		verified = false
 	}
-	wErr := cleanseNBSPs(w.Error)
-	if got := r.ErrorOutput(); got != wErr {
-		differences = append(differences, fmt.Sprintf("error output = %s", cleanseNBSPs(cmp.Diff(wErr, got))))
+	wErr := r.normalize(w.Error)
+	if errors != wErr {
+		differences = append(differences, fmt.Sprintf("error output = %s", ReplaceNBSPs(cmp.Diff(wErr, errors))))
 		verified = false
 	}
-	wLog := cleanseNBSPs(w.Log)
-	if got := r.LogOutput(); got != wLog {
-		differences = append(differences, fmt.Sprintf("log output = %s", cleanseNBSPs(cmp.Diff(wLog, got))))
+	wLog := r.normalize(w.Log)
+	if log != wLog {
+		differences = append(differences, fmt.Sprintf("log output = %s", ReplaceNBSPs(cmp.Diff(wLog, log))))
 		verified = false
 	}
 	return
@@ -232,9 +444,24 @@ type TestingReporter interface {
 // any differences were recorded, and reporting them if there were any
 // differences.
 func (r *Recorder) Report(t TestingReporter, header string, w WantedRecording) {
-	if differences, verified := r.Verify(w); !verified {
+	differences, verified := r.Verify(w)
+	reportDifferences(t, header, differences, verified)
+}
+
+// ReportSinceCheckpoint is the checkpoint-aware counterpart of Report: it
+// reports any differences between the output recorded since the most recent
+// checkpoint and the expected output.
+func (r *Recorder) ReportSinceCheckpoint(t TestingReporter, header string, w WantedRecording) {
+	differences, verified := r.VerifySinceCheckpoint(w)
+	reportDifferences(t, header, differences, verified)
+}
+
+// reportDifferences must be called directly from the exported Report function
+// so that the location it reports is that of the test code
+func reportDifferences(t TestingReporter, header string, differences []string, verified bool) {
+	if !verified {
 		var location string
-		if _, file, line, ok := runtime.Caller(1); ok {
+		if _, file, line, ok := runtime.Caller(2); ok {
 			canonicalFile := strings.ReplaceAll(file, "/", "\\")
 			location = fmt.Sprintf("called from %s:%d: ", canonicalFile, line)
 		}
@@ -250,6 +477,15 @@ func NewRecordingLogger() *RecordingLogger {
 	return &RecordingLogger{writer: &bytes.Buffer{}}
 }
 
+// WithRedaction makes the RecordingLogger apply a Redaction to the fields of
+// the log messages it records, so that tests can verify that sensitive values
+// are masked; it returns the RecordingLogger so that calls can be chained onto
+// NewRecordingLogger().
+func (rl *RecordingLogger) WithRedaction(r *Redaction) *RecordingLogger {
+	rl.redaction = r
+	return rl
+}
+
 func (rl *RecordingLogger) String() string {
 	return rl.writer.String()
 }
@@ -290,10 +526,14 @@ func (rl *RecordingLogger) Fatal(msg string, fields map[string]any) {
 }
 
 func (rl *RecordingLogger) log(level, msg string, fields map[string]any) {
+	fmt.Fprintln(rl.writer, formatLogEntry(level, msg, rl.redaction.Apply(fields)))
+}
+
+func formatLogEntry(level, msg string, fields map[string]any) string {
 	parts := make([]string, 0, len(fields))
 	for k, v := range fields {
 		parts = append(parts, fmt.Sprintf("%s='%v'", k, v))
 	}
 	sort.Strings(parts)
-	fmt.Fprintf(rl.writer, "level='%s' %s msg='%s'\n", level, strings.Join(parts, " "), msg)
+	return fmt.Sprintf("level='%s' %s msg='%s'", level, strings.Join(parts, " "), msg)
 }
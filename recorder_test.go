@@ -1,6 +1,8 @@
 package output_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -327,6 +329,54 @@ func TestRecorder_Verify(t *testing.T) {
 	}
 }
 
+func TestRecorder_Checkpoint(t *testing.T) {
+	o := output.NewRecorder()
+	o.IncrementTab(2)
+	o.BeginConsoleList(true)
+	o.ConsolePrintln("step one")
+	o.ErrorPrintln("step one failed")
+	o.Log(output.Info, "step one", map[string]any{"step": 1})
+	o.Checkpoint()
+	o.ReportSinceCheckpoint(t, "after checkpoint", output.WantedRecording{})
+	o.ConsolePrintln("step two")
+	o.Log(output.Info, "step two", map[string]any{"step": 2})
+	o.ReportSinceCheckpoint(t, "step two", output.WantedRecording{
+		Console: "   2. step two\n",
+		Log:     "level='info' step='2' msg='step two'\n",
+	})
+	o.Report(t, "everything", output.WantedRecording{
+		Console: "   1. step one\n   2. step two\n",
+		Error:   "step one failed\n",
+		Log:     "level='info' step='1' msg='step one'\nlevel='info' step='2' msg='step two'\n",
+	})
+	vr := newVerificationReporter()
+	o.ReportSinceCheckpoint(vr, "step two", output.WantedRecording{Console: "   2. step two\n"})
+	if len(vr.buffer) != 1 || !strings.Contains(vr.buffer[0], "step two log output = ") {
+		t.Errorf("Recorder.ReportSinceCheckpoint() reported %q", vr.buffer)
+	}
+	if !strings.HasPrefix(vr.buffer[0], "called from ") || !strings.Contains(vr.buffer[0], "recorder_test.go:") {
+		t.Errorf("Recorder.ReportSinceCheckpoint() reported wrong location %q", vr.buffer[0])
+	}
+}
+
+func TestRecorder_Reset(t *testing.T) {
+	o := output.NewRecorder()
+	o.IncrementTab(2)
+	o.BeginConsoleList(true)
+	o.ConsolePrintln("step one")
+	o.ErrorPrintln("step one failed")
+	o.Log(output.Info, "step one", nil)
+	o.Checkpoint()
+	o.Reset()
+	o.Report(t, "after reset", output.WantedRecording{})
+	o.ReportSinceCheckpoint(t, "after reset", output.WantedRecording{})
+	o.ConsolePrintln("step two")
+	o.ReportSinceCheckpoint(t, "step two", output.WantedRecording{Console: "   2. step two\n"})
+	if got := o.Tab(); got != 2 {
+		t.Errorf("Recorder.Reset() tab = %d, want 2", got)
+	}
+}
+
 type verificationReporter struct {
 	buffer []string
 }
@@ -640,7 +690,11 @@ func TestRecorder_IsConsoleTTY(t *testing.T) {
 	tests := map[string]struct {
 		r    *output.Recorder
 		want bool
-	}{"simple": {r: output.NewRecorder(), want: false}}
+	}{
+		"simple":      {r: output.NewRecorder(), want: false},
+		"console tty": {r: output.NewRecorder().SimulateConsoleTTY(80, 24), want: true},
+		"error tty":   {r: output.NewRecorder().SimulateErrorTTY(80, 24), want: false},
+	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			if got := tt.r.IsConsoleTTY(); got != tt.want {
@@ -654,7 +708,11 @@ func TestRecorder_IsErrorTTY(t *testing.T) {
 	tests := map[string]struct {
 		r    *output.Recorder
 		want bool
-	}{"simple": {r: output.NewRecorder(), want: false}}
+	}{
+		"simple":      {r: output.NewRecorder(), want: false},
+		"console tty": {r: output.NewRecorder().SimulateConsoleTTY(80, 24), want: false},
+		"error tty":   {r: output.NewRecorder().SimulateErrorTTY(80, 24), want: true},
+	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			if got := tt.r.IsErrorTTY(); got != tt.want {
@@ -664,6 +722,90 @@ func TestRecorder_IsErrorTTY(t *testing.T) {
 	}
 }
 
+func TestRecorder_TerminalSize(t *testing.T) {
+	tests := map[string]struct {
+		r                 *output.Recorder
+		wantConsoleWidth  int
+		wantConsoleHeight int
+		wantConsoleOk     bool
+		wantErrorWidth    int
+		wantErrorHeight   int
+		wantErrorOk       bool
+	}{
+		"no tty": {r: output.NewRecorder()},
+		"console only": {
+			r:                 output.NewRecorder().SimulateConsoleTTY(80, 24),
+			wantConsoleWidth:  80,
+			wantConsoleHeight: 24,
+			wantConsoleOk:     true,
+		},
+		"independent sizes": {
+			r:                 output.NewRecorder().SimulateConsoleTTY(80, 24).SimulateErrorTTY(132, 50),
+			wantConsoleWidth:  80,
+			wantConsoleHeight: 24,
+			wantConsoleOk:     true,
+			wantErrorWidth:    132,
+			wantErrorHeight:   50,
+			wantErrorOk:       true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sizer output.TerminalSizer = tt.r
+			gotWidth, gotHeight, gotOk := sizer.ConsoleTerminalSize()
+			if gotWidth != tt.wantConsoleWidth || gotHeight != tt.wantConsoleHeight || gotOk != tt.wantConsoleOk {
+				t.Errorf("Recorder.ConsoleTerminalSize() = %d, %d, %t, want %d, %d, %t",
+					gotWidth, gotHeight, gotOk, tt.wantConsoleWidth, tt.wantConsoleHeight, tt.wantConsoleOk)
+			}
+			gotWidth, gotHeight, gotOk = sizer.ErrorTerminalSize()
+			if gotWidth != tt.wantErrorWidth || gotHeight != tt.wantErrorHeight || gotOk != tt.wantErrorOk {
+				t.Errorf("Recorder.ErrorTerminalSize() = %d, %d, %t, want %d, %d, %t",
+					gotWidth, gotHeight, gotOk, tt.wantErrorWidth, tt.wantErrorHeight, tt.wantErrorOk)
+			}
+		})
+	}
+}
+
+func TestTerminalSizeOf(t *testing.T) {
+	r := output.NewRecorder().SimulateConsoleTTY(80, 24).SimulateErrorTTY(132, 50)
+	tests := map[string]struct {
+		b       output.Bus
+		wantNil bool
+	}{
+		"recorder":    {b: r},
+		"caller bus":  {b: output.NewCallerBus(r)},
+		"partial bus": {b: output.NewPartialBus(r, nil)},
+		"capturing bus": {
+			b: output.NewCapturingBus(output.NewCallerBus(r), &bytes.Buffer{}),
+		},
+		"context bus": {
+			b: output.FromContext(output.ContextWithFields(output.NewContext(context.Background(), r),
+				map[string]any{"request": 1})),
+		},
+		"nil": {wantNil: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sizer := output.TerminalSizeOf(tt.b)
+			if tt.wantNil {
+				if sizer != nil {
+					t.Errorf("TerminalSizeOf() = %v, want nil", sizer)
+				}
+				return
+			}
+			if sizer == nil {
+				t.Fatalf("TerminalSizeOf() = nil")
+			}
+			if width, height, ok := sizer.ConsoleTerminalSize(); width != 80 || height != 24 || !ok {
+				t.Errorf("ConsoleTerminalSize() = %d, %d, %t, want 80, 24, true", width, height, ok)
+			}
+			if width, height, ok := sizer.ErrorTerminalSize(); width != 132 || height != 50 || !ok {
+				t.Errorf("ErrorTerminalSize() = %d, %d, %t, want 132, 50, true", width, height, ok)
+			}
+		})
+	}
+}
+
 func Test_Recorder_IncrementTab(t *testing.T) {
 	tests := map[string]struct {
 		initialTab uint8
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+type (
+	// RecoveryOptions configures RecoverPanic.
+	RecoveryOptions struct {
+		// Message is printed on the error channel, followed by the panic
+		// value; if it is empty, DefaultPanicMessage is used
+		Message string
+		// Repanic makes RecoverPanic re-raise the panic once it has been
+		// reported
+		Repanic bool
+		// ExitCode, if not nil, is set to PanicExitCode when a panic is
+		// recovered (and not re-raised), so that the caller can return it as
+		// the program's exit status
+		ExitCode *int
+	}
+)
+
+const (
+	// DefaultPanicMessage is the message RecoverPanic prints when
+	// RecoveryOptions.Message is empty.
+	DefaultPanicMessage = "An unexpected internal error occurred"
+	// PanicExitCode is the exit code RecoverPanic reports through
+	// RecoveryOptions.ExitCode; it matches the status with which the Go
+	// runtime exits after an unrecovered panic.
+	PanicExitCode = 2
+	// PanicValueKey and PanicStackKey are the keys of the fields RecoverPanic
+	// logs.
+	PanicValueKey = "panic_value"
+	PanicStackKey = "panic_stack"
+)
+
+// RecoverPanic reports a panic through b; it must be called directly by a
+// deferred statement, e.g.,
+//
+//	defer output.RecoverPanic(o, output.RecoveryOptions{ExitCode: &exitCode})
+//
+// If the calling goroutine is panicking, RecoverPanic stops the panic, prints a
+// friendly message and the panic value with ErrorPrintln, logs the panic value
+// and the stack trace at the Panic level, and flushes b (see Flush). A Logger
+// that calls panic() when asked to log a panic message, as production loggers
+// usually do, does not interrupt the recovery. RecoverPanic then re-raises the
+// panic if opts.Repanic is set, or else sets *opts.ExitCode, if it is not nil.
+func RecoverPanic(b Bus, opts RecoveryOptions) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	reportPanic(b, value, string(debug.Stack()), opts.Message)
+	if opts.Repanic {
+		panic(value)
+	}
+	if opts.ExitCode != nil {
+		*opts.ExitCode = PanicExitCode
+	}
+}
+
+func reportPanic(b Bus, value any, stack, msg string) {
+	if msg == "" {
+		msg = DefaultPanicMessage
+	}
+	b.ErrorPrintln(fmt.Sprintf("%s: %v", msg, value))
+	func() {
+		defer func() {
+			// the Logger's own panic has served its purpose
+			_ = recover()
+		}()
+		b.Log(Panic, msg, map[string]any{
+			PanicValueKey: fmt.Sprint(value),
+			PanicStackKey: stack,
+		})
+	}()
+	Flush(b)
+}
+
+// Flush flushes any buffered output held by b: if b (or the Bus it wraps, as
+// reported by an Unwrap() Bus function) has a Flush function, Flush calls it.
+// The Bus implementations returned by NewDefaultBus and NewCustomBus flush
+// their console writer, error writer, and Logger, each of which is flushed if
+// it has a Flush function with no parameters and either no result or an error
+// result, such as *bufio.Writer, *LineWriter, *AsyncLogger, and
+// *SamplingLogger do.
+func Flush(b Bus) {
+	for b != nil {
+		if flusher, ok := b.(interface{ Flush() }); ok {
+			flusher.Flush()
+			return
+		}
+		wrapper, ok := b.(interface{ Unwrap() Bus })
+		if !ok {
+			return
+		}
+		b = wrapper.Unwrap()
+	}
+}
+
+// Flush flushes the Bus's console writer, error writer, and Logger, where they
+// support it.
+func (b *bus) Flush() {
+	flushValue(b.consoleWriter)
+	flushValue(b.errorWriter)
+	flushValue(b.logger)
+}
+
+func flushValue(v any) {
+	switch flusher := v.(type) {
+	case interface{ Flush() }:
+		flusher.Flush()
+	case interface{ Flush() error }:
+		_ = flusher.Flush()
+	case interface{ Unwrap() io.Writer }:
+		flushValue(flusher.Unwrap())
+	}
+}
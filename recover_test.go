@@ -0,0 +1,90 @@
+package output_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	errorOutput := &bytes.Buffer{}
+	bufferedErrors := bufio.NewWriter(errorOutput)
+	logger := output.NewMockLogger(t).Expect(output.Panic, "command failed", nil).PanicOnPanic()
+	o := output.NewCustomBus(output.NilWriter{}, bufferedErrors, logger)
+	exitCode := 0
+	func() {
+		defer output.RecoverPanic(o, output.RecoveryOptions{Message: "command failed", ExitCode: &exitCode})
+		panic("index out of range")
+	}()
+	if exitCode != output.PanicExitCode {
+		t.Errorf("RecoverPanic() exit code = %d, want %d", exitCode, output.PanicExitCode)
+	}
+	if got, want := errorOutput.String(), "command failed: index out of range\n"; got != want {
+		t.Errorf("RecoverPanic() error output = %q, want %q", got, want)
+	}
+	calls := logger.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("RecoverPanic() logged %d messages, want 1", len(calls))
+	}
+	if got := calls[0].Fields[output.PanicValueKey]; got != "index out of range" {
+		t.Errorf("RecoverPanic() logged panic value %v, want %q", got, "index out of range")
+	}
+	if stack, _ := calls[0].Fields[output.PanicStackKey].(string); !strings.Contains(stack, "TestRecoverPanic") {
+		t.Errorf("RecoverPanic() logged stack %q, want it to include TestRecoverPanic", stack)
+	}
+}
+
+func TestRecoverPanic_repanic(t *testing.T) {
+	o := output.NewRecorder()
+	defer func() {
+		if got := recover(); got != "boom" {
+			t.Errorf("RecoverPanic() re-raised %v, want %q", got, "boom")
+		}
+		if got := o.ErrorOutput(); got != output.DefaultPanicMessage+": boom\n" {
+			t.Errorf("RecoverPanic() error output = %q", got)
+		}
+		if !strings.HasPrefix(o.LogOutput(), "level='panic' panic_stack='") {
+			t.Errorf("RecoverPanic() log output = %q", o.LogOutput())
+		}
+	}()
+	defer output.RecoverPanic(o, output.RecoveryOptions{Repanic: true})
+	panic("boom")
+}
+
+func TestRecoverPanic_noPanic(t *testing.T) {
+	o := output.NewRecorder()
+	exitCode := 0
+	func() {
+		defer output.RecoverPanic(o, output.RecoveryOptions{ExitCode: &exitCode})
+	}()
+	if exitCode != 0 {
+		t.Errorf("RecoverPanic() exit code = %d, want 0", exitCode)
+	}
+	o.Report(t, "RecoverPanic()", output.WantedRecording{})
+}
+
+func TestFlush(t *testing.T) {
+	console := &bytes.Buffer{}
+	bufferedConsole := bufio.NewWriter(console)
+	logged := &bytes.Buffer{}
+	logger := output.NewAsyncLogger(output.NewJSONLogger(logged), 10, output.BlockWhenFull)
+	defer func() {
+		_ = logger.Close()
+	}()
+	o := output.NewCustomBus(output.NewPrefixWriter(bufferedConsole, output.LinePrefix{Tag: "app"}), output.NilWriter{},
+		logger)
+	o.ConsolePrintln("hello")
+	o.Log(output.Info, "hello", nil)
+	output.Flush(output.NewCapturingBus(o, &bytes.Buffer{}))
+	if got := console.String(); got != "[app] hello\n" {
+		t.Errorf("Flush() console output = %q, want %q", got, "[app] hello\n")
+	}
+	if !strings.Contains(logged.String(), `"msg":"hello"`) {
+		t.Errorf("Flush() log output = %q", logged.String())
+	}
+	output.Flush(output.NewRecorder())
+	output.Flush(nil)
+}
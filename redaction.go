@@ -0,0 +1,188 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+)
+
+type (
+	// Secret wraps a value, such as a password or a token, that must not be
+	// written to any output: formatting a Secret with fmt, or encoding it as
+	// JSON, produces "[REDACTED]". Log field values of type Secret are always
+	// redacted by a Redaction.
+	Secret struct {
+		value any
+	}
+
+	// Redactor is a custom redaction rule: given a log field's key and value,
+	// it returns the value to log in its place and true, or, if the field is
+	// of no interest to it, false.
+	Redactor func(key string, value any) (redacted any, ok bool)
+
+	// Redaction masks sensitive log field values. A field's value is masked
+	// if it is a Secret, or if its key matches one of the Redaction's key
+	// patterns; otherwise, the Redaction's custom Redactors are consulted, in
+	// order. Fields whose values are of type map[string]any are redacted
+	// recursively.
+	Redaction struct {
+		keyPatterns []string
+		redactors   []Redactor
+	}
+
+	// RedactingLogger is a Logger that applies a Redaction to the fields of
+	// each log message before passing the log message to another Logger.
+	RedactingLogger struct {
+		logger    Logger
+		redaction *Redaction
+	}
+)
+
+// RedactedValue is the value logged in place of a redacted value.
+const RedactedValue = "[REDACTED]"
+
+// NewSecret returns a Secret wrapping value.
+func NewSecret(value any) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the wrapped value.
+func (s Secret) Reveal() any {
+	return s.value
+}
+
+// String returns RedactedValue.
+func (s Secret) String() string {
+	return RedactedValue
+}
+
+// Format writes RedactedValue, regardless of the verb and flags.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(RedactedValue))
+}
+
+// MarshalJSON encodes RedactedValue as a JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + RedactedValue + `"`), nil
+}
+
+// NewRedaction returns a Redaction that masks the values of fields whose keys
+// match any of the specified patterns, ignoring case; patterns use the syntax
+// of path.Match, e.g., "*password*" or "token". It returns an error if any
+// pattern is malformed.
+func NewRedaction(keyPatterns ...string) (*Redaction, error) {
+	r := &Redaction{}
+	for _, pattern := range keyPatterns {
+		lowerPattern := strings.ToLower(pattern)
+		if _, err := path.Match(lowerPattern, ""); err != nil {
+			return nil, fmt.Errorf("output: invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.keyPatterns = append(r.keyPatterns, lowerPattern)
+	}
+	return r, nil
+}
+
+// WithRedactors adds custom Redactors to the Redaction; it returns the
+// Redaction so that calls can be chained.
+func (r *Redaction) WithRedactors(redactors ...Redactor) *Redaction {
+	r.redactors = append(r.redactors, redactors...)
+	return r
+}
+
+// Apply returns fields with the sensitive values masked; fields itself is not
+// modified. If nothing needs to be masked, fields is returned as is.
+func (r *Redaction) Apply(fields map[string]any) map[string]any {
+	if r == nil {
+		return fields
+	}
+	redacted, _ := r.apply(fields)
+	return redacted
+}
+
+func (r *Redaction) apply(fields map[string]any) (map[string]any, bool) {
+	var redacted map[string]any
+	for k, v := range fields {
+		value, changed := r.redact(k, v)
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = maps.Clone(fields)
+		}
+		redacted[k] = value
+	}
+	if redacted == nil {
+		return fields, false
+	}
+	return redacted, true
+}
+
+func (r *Redaction) redact(k string, v any) (any, bool) {
+	if _, ok := v.(Secret); ok {
+		return RedactedValue, true
+	}
+	if r.keyMatches(k) {
+		return RedactedValue, true
+	}
+	for _, redactor := range r.redactors {
+		if value, ok := redactor(k, v); ok {
+			return value, true
+		}
+	}
+	if nested, ok := v.(map[string]any); ok {
+		return r.apply(nested)
+	}
+	return v, false
+}
+
+func (r *Redaction) keyMatches(k string) bool {
+	lowerKey := strings.ToLower(k)
+	for _, pattern := range r.keyPatterns {
+		if matched, _ := path.Match(pattern, lowerKey); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRedactingLogger returns a RedactingLogger that applies r to log message
+// fields before passing the log messages to l.
+func NewRedactingLogger(l Logger, r *Redaction) *RedactingLogger {
+	return &RedactingLogger{logger: l, redaction: r}
+}
+
+// Trace redacts and passes on a trace log message.
+func (rl *RedactingLogger) Trace(msg string, fields map[string]any) {
+	rl.logger.Trace(msg, rl.redaction.Apply(fields))
+}
+
+// Debug redacts and passes on a debug log message.
+func (rl *RedactingLogger) Debug(msg string, fields map[string]any) {
+	rl.logger.Debug(msg, rl.redaction.Apply(fields))
+}
+
+// Info redacts and passes on an info log message.
+func (rl *RedactingLogger) Info(msg string, fields map[string]any) {
+	rl.logger.Info(msg, rl.redaction.Apply(fields))
+}
+
+// Warning redacts and passes on a warning log message.
+func (rl *RedactingLogger) Warning(msg string, fields map[string]any) {
+	rl.logger.Warning(msg, rl.redaction.Apply(fields))
+}
+
+// Error redacts and passes on an error log message.
+func (rl *RedactingLogger) Error(msg string, fields map[string]any) {
+	rl.logger.Error(msg, rl.redaction.Apply(fields))
+}
+
+// Panic redacts and passes on a panic log message.
+func (rl *RedactingLogger) Panic(msg string, fields map[string]any) {
+	rl.logger.Panic(msg, rl.redaction.Apply(fields))
+}
+
+// Fatal redacts and passes on a fatal log message.
+func (rl *RedactingLogger) Fatal(msg string, fields map[string]any) {
+	rl.logger.Fatal(msg, rl.redaction.Apply(fields))
+}
@@ -0,0 +1,120 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestSecret(t *testing.T) {
+	s := output.NewSecret("hunter2")
+	if got := s.Reveal(); got != "hunter2" {
+		t.Errorf("Secret.Reveal() = %v", got)
+	}
+	for _, format := range []string{"%v", "%s", "%q", "%+v", "%#v", "%d", "%x"} {
+		if got := fmt.Sprintf(format, s); got != output.RedactedValue {
+			t.Errorf("fmt.Sprintf(%q, Secret) = %q", format, got)
+		}
+	}
+	if got := s.String(); got != output.RedactedValue {
+		t.Errorf("Secret.String() = %q", got)
+	}
+	if got, _ := json.Marshal(map[string]any{"s": s}); string(got) != `{"s":"[REDACTED]"}` {
+		t.Errorf("json.Marshal(Secret) = %s", got)
+	}
+}
+
+func TestNewRedaction(t *testing.T) {
+	if _, err := output.NewRedaction("[bad"); err == nil {
+		t.Errorf("NewRedaction() expected error for malformed pattern")
+	}
+}
+
+func TestRedaction_Apply(t *testing.T) {
+	r, _ := output.NewRedaction("*password*", "token")
+	r.WithRedactors(func(key string, value any) (any, bool) {
+		if s, ok := value.(string); ok && strings.HasPrefix(s, "sk_") {
+			return "sk_***", true
+		}
+		return nil, false
+	})
+	tests := map[string]struct {
+		r      *output.Redaction
+		fields map[string]any
+		want   string
+	}{
+		"nothing to redact": {
+			r:      r,
+			fields: map[string]any{"user": "joe"},
+			want:   "map[user:joe]",
+		},
+		"nil redaction": {
+			fields: map[string]any{"password": "hunter2"},
+			want:   "map[password:hunter2]",
+		},
+		"keys, types, and custom": {
+			r: r,
+			fields: map[string]any{
+				"DB_Password": "hunter2",
+				"Token":       "abc",
+				"tokens":      "not matched",
+				"api":         output.NewSecret(42),
+				"key":         "sk_live_123",
+				"nested":      map[string]any{"password": "x", "ok": 1},
+				"plain":       map[string]any{"ok": 1},
+			},
+			want: "map[DB_Password:[REDACTED] Token:[REDACTED] api:[REDACTED] key:sk_*** " +
+				"nested:map[ok:1 password:[REDACTED]] plain:map[ok:1] tokens:not matched]",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			original := fmt.Sprint(tt.fields)
+			if got := fmt.Sprint(tt.r.Apply(tt.fields)); got != tt.want {
+				t.Errorf("Redaction.Apply() = %s, want %s", got, tt.want)
+			}
+			if got := fmt.Sprint(tt.fields); got != original {
+				t.Errorf("Redaction.Apply() modified its input: %s", got)
+			}
+		})
+	}
+}
+
+func TestRedactingLogger(t *testing.T) {
+	r, _ := output.NewRedaction("password")
+	w := &bytes.Buffer{}
+	rl := output.NewRedactingLogger(output.NewLogfmtLogger(w), r)
+	rl.Info("login", map[string]any{"user": "joe", "password": "hunter2"})
+	if got := w.String(); !strings.HasSuffix(got, "msg=login password=[REDACTED] user=joe\n") {
+		t.Errorf("RedactingLogger wrote %q", got)
+	}
+	ml := output.NewMockLogger(t).
+		Expect(output.Trace, "t", map[string]any{"password": output.RedactedValue}).
+		Expect(output.Debug, "d", map[string]any{"password": output.RedactedValue}).
+		Expect(output.Warning, "w", map[string]any{"password": output.RedactedValue}).
+		Expect(output.Error, "e", map[string]any{"password": output.RedactedValue}).
+		Expect(output.Panic, "p", map[string]any{"password": output.RedactedValue}).
+		Expect(output.Fatal, "f", map[string]any{"password": output.RedactedValue})
+	redacting := output.NewRedactingLogger(ml, r)
+	fields := map[string]any{"password": "hunter2"}
+	redacting.Trace("t", fields)
+	redacting.Debug("d", fields)
+	redacting.Warning("w", fields)
+	redacting.Error("e", fields)
+	redacting.Panic("p", fields)
+	redacting.Fatal("f", fields)
+	ml.AssertExpectations()
+}
+
+func TestRecorder_WithRedaction(t *testing.T) {
+	r, _ := output.NewRedaction("*token*")
+	o := output.NewRecorder().WithRedaction(r)
+	o.Log(output.Info, "call", map[string]any{"auth_token": "abc", "secret": output.NewSecret("x"), "n": 1})
+	o.Report(t, "Recorder.WithRedaction()", output.WantedRecording{
+		Log: "level='info' auth_token='[REDACTED]' n='1' secret='[REDACTED]' msg='call'\n",
+	})
+}
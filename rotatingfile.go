@@ -0,0 +1,315 @@
+package output
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	// WritableFile is the subset of *os.File that a RotatingFile writes to.
+	WritableFile interface {
+		io.Writer
+		io.Closer
+	}
+
+	// FileSystem abstracts the file operations performed by a RotatingFile, so
+	// that tests can substitute an in-memory implementation; OSFileSystem is
+	// the production implementation.
+	FileSystem interface {
+		// OpenFile opens the named file for writing, as os.OpenFile does
+		OpenFile(name string, flag int, perm os.FileMode) (WritableFile, error)
+		// Open opens the named file for reading, as os.Open does
+		Open(name string) (io.ReadCloser, error)
+		// Stat returns the named file's FileInfo, as os.Stat does
+		Stat(name string) (os.FileInfo, error)
+		// Rename renames a file, as os.Rename does
+		Rename(oldPath, newPath string) error
+		// Remove removes the named file, as os.Remove does
+		Remove(name string) error
+	}
+
+	// OSFileSystem is the FileSystem implemented by the os package.
+	OSFileSystem struct{}
+
+	// RotationPolicy specifies when a RotatingFile rotates, and what it does
+	// with the files it rotates out.
+	RotationPolicy struct {
+		// MaxSize is the size, in bytes, that the file may reach before it is
+		// rotated; zero means that the file is never rotated because of its size
+		MaxSize int64
+		// MaxAge is how long after the file was started it is rotated; zero
+		// means that the file is never rotated because of its age. The age
+		// survives Reopen and process restarts: when a RotatingFile first
+		// opens an existing, non-empty file, it takes the file to have been
+		// started when the newest backup was last modified (that is, when the
+		// backup was rotated out), or, if there is no backup, when the file
+		// itself was last modified
+		MaxAge time.Duration
+		// MaxBackups is the number of rotated files to keep, named after the
+		// file with the suffixes .1 (newest) through .MaxBackups (oldest); when
+		// MaxBackups is zero, rotated files are deleted
+		MaxBackups int
+		// Compress specifies whether rotated files are compressed with gzip,
+		// which adds the suffix .gz to their names
+		Compress bool
+	}
+
+	// RotatingFile is an io.WriteCloser that appends to a file, and rotates
+	// the file according to its RotationPolicy; the file is opened (and
+	// created, if necessary) by the first write. RotatingFile is safe for
+	// concurrent use.
+	RotatingFile struct {
+		lock      sync.Mutex
+		path      string
+		policy    RotationPolicy
+		fs        FileSystem
+		clock     Clock
+		file      WritableFile
+		size      int64
+		startedAt time.Time
+	}
+
+	// RotatingFileLogger is a JSONLogger that writes to a RotatingFile.
+	RotatingFileLogger struct {
+		*JSONLogger
+		file *RotatingFile
+	}
+)
+
+const (
+	logFileFlags       = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	logFilePermissions = 0o644
+)
+
+// OpenFile opens the named file for writing.
+func (OSFileSystem) OpenFile(name string, flag int, perm os.FileMode) (WritableFile, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+// Open opens the named file for reading.
+func (OSFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+// Stat returns the named file's FileInfo.
+func (OSFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Rename renames a file.
+func (OSFileSystem) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+// Remove removes the named file.
+func (OSFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// NewRotatingFile returns a RotatingFile that writes to the file at path,
+// using the os package for file operations.
+func NewRotatingFile(path string, policy RotationPolicy) *RotatingFile {
+	return &RotatingFile{path: path, policy: policy, fs: OSFileSystem{}}
+}
+
+// WithFileSystem sets the FileSystem that the RotatingFile uses; it returns the
+// RotatingFile so that calls can be chained onto NewRotatingFile().
+func (rf *RotatingFile) WithFileSystem(fileSystem FileSystem) *RotatingFile {
+	rf.fs = fileSystem
+	return rf
+}
+
+// WithClock sets the Clock that the RotatingFile uses to determine the age of
+// the file; it returns the RotatingFile so that calls can be chained onto
+// NewRotatingFile().
+func (rf *RotatingFile) WithClock(c Clock) *RotatingFile {
+	rf.clock = c
+	return rf
+}
+
+// Write appends p to the file, first rotating the file if the write would
+// exceed the maximum size, or if the file has exceeded its maximum age.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.needsRotation(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (rf *RotatingFile) Rotate() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	return rf.rotate()
+}
+
+// Reopen closes the file, if it is open; the next write reopens it. Reopen is
+// intended for use after an external tool, such as logrotate, has moved the
+// file.
+func (rf *RotatingFile) Reopen() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	return rf.close()
+}
+
+// Close closes the file.
+func (rf *RotatingFile) Close() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	return rf.close()
+}
+
+func (rf *RotatingFile) needsRotation(writeSize int64) bool {
+	if rf.policy.MaxSize > 0 && rf.size > 0 && rf.size+writeSize > rf.policy.MaxSize {
+		return true
+	}
+	return rf.policy.MaxAge > 0 && rf.clock.now().Sub(rf.startedAt) >= rf.policy.MaxAge
+}
+
+func (rf *RotatingFile) open() error {
+	file, err := rf.fs.OpenFile(rf.path, logFileFlags, logFilePermissions)
+	if err != nil {
+		return err
+	}
+	rf.file = file
+	rf.size = 0
+	if info, statErr := rf.fs.Stat(rf.path); statErr == nil {
+		rf.size = info.Size()
+		if rf.size > 0 && rf.startedAt.IsZero() {
+			rf.startedAt = rf.estimateStart(info)
+		}
+	}
+	if rf.size == 0 {
+		rf.startedAt = rf.clock.now()
+	}
+	return nil
+}
+
+// estimateStart estimates when an existing file, opened for the first time,
+// was started: when the newest backup was rotated out, if there is one, or
+// else when the file was last modified
+func (rf *RotatingFile) estimateStart(info os.FileInfo) time.Time {
+	for _, compressed := range []bool{false, true} {
+		if backup, err := rf.fs.Stat(rf.backupName(1, compressed)); err == nil {
+			return backup.ModTime()
+		}
+	}
+	return info.ModTime()
+}
+
+func (rf *RotatingFile) close() error {
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+func (rf *RotatingFile) rotate() error {
+	if err := rf.close(); err != nil {
+		return err
+	}
+	rf.startedAt = time.Time{}
+	if err := rf.shiftBackups(); err != nil {
+		return err
+	}
+	return rf.open()
+}
+
+func (rf *RotatingFile) backupName(n int, compressed bool) string {
+	name := fmt.Sprintf("%s.%d", rf.path, n)
+	if compressed {
+		name += ".gz"
+	}
+	return name
+}
+
+// shiftBackups discards the oldest backup, renames the remaining backups to
+// make room for the newest one, and then moves the current file into the
+// newest backup's place, compressing it if the policy calls for compression
+func (rf *RotatingFile) shiftBackups() error {
+	if rf.policy.MaxBackups <= 0 {
+		return ignoreNotExist(rf.fs.Remove(rf.path))
+	}
+	for _, compressed := range []bool{false, true} {
+		if err := ignoreNotExist(rf.fs.Remove(rf.backupName(rf.policy.MaxBackups, compressed))); err != nil {
+			return err
+		}
+		for n := rf.policy.MaxBackups - 1; n >= 1; n-- {
+			err := rf.fs.Rename(rf.backupName(n, compressed), rf.backupName(n+1, compressed))
+			if err = ignoreNotExist(err); err != nil {
+				return err
+			}
+		}
+	}
+	newest := rf.backupName(1, false)
+	if err := ignoreNotExist(rf.fs.Rename(rf.path, newest)); err != nil {
+		return err
+	}
+	if rf.policy.Compress {
+		return rf.compress(newest)
+	}
+	return nil
+}
+
+func (rf *RotatingFile) compress(name string) error {
+	source, err := rf.fs.Open(name)
+	if err != nil {
+		return ignoreNotExist(err)
+	}
+	defer func() {
+		_ = source.Close()
+	}()
+	target, err := rf.fs.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, logFilePermissions)
+	if err != nil {
+		return err
+	}
+	compressor := gzip.NewWriter(target)
+	_, err = io.Copy(compressor, source)
+	err = errors.Join(err, compressor.Close(), target.Close())
+	if err != nil {
+		return err
+	}
+	return rf.fs.Remove(name)
+}
+
+func ignoreNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// NewRotatingFileLogger returns a RotatingFileLogger that writes JSON log
+// messages to f.
+func NewRotatingFileLogger(f *RotatingFile) *RotatingFileLogger {
+	return &RotatingFileLogger{JSONLogger: NewJSONLogger(f), file: f}
+}
+
+// File returns the RotatingFile that the RotatingFileLogger writes to.
+func (rfl *RotatingFileLogger) File() *RotatingFile {
+	return rfl.file
+}
+
+// Close closes the RotatingFile that the RotatingFileLogger writes to.
+func (rfl *RotatingFileLogger) Close() error {
+	return rfl.file.Close()
+}
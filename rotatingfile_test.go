@@ -0,0 +1,330 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memFileInfo struct {
+	os.FileInfo
+	size    int64
+	modTime time.Time
+}
+
+func (mfi memFileInfo) Size() int64 {
+	return mfi.size
+}
+
+func (mfi memFileInfo) ModTime() time.Time {
+	return mfi.modTime
+}
+
+type memFile struct {
+	fs   *memFileSystem
+	name string
+}
+
+func (mf *memFile) Write(p []byte) (int, error) {
+	mf.fs.lock.Lock()
+	defer mf.fs.lock.Unlock()
+	if mf.fs.failWrites {
+		return 0, errors.New("disk full")
+	}
+	return mf.fs.files[mf.name].Write(p)
+}
+
+func (mf *memFile) Close() error {
+	return nil
+}
+
+type memFileSystem struct {
+	lock       sync.Mutex
+	files      map[string]*bytes.Buffer
+	modTimes   map[string]time.Time
+	opens      int
+	failOpens  bool
+	failWrites bool
+}
+
+func newMemFileSystem() *memFileSystem {
+	return &memFileSystem{files: map[string]*bytes.Buffer{}, modTimes: map[string]time.Time{}}
+}
+
+func (mfs *memFileSystem) OpenFile(name string, flag int, _ os.FileMode) (WritableFile, error) {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	if mfs.failOpens {
+		return nil, fs.ErrPermission
+	}
+	mfs.opens++
+	if _, ok := mfs.files[name]; !ok || flag&os.O_TRUNC != 0 {
+		mfs.files[name] = &bytes.Buffer{}
+	}
+	return &memFile{fs: mfs, name: name}, nil
+}
+
+func (mfs *memFileSystem) Open(name string) (io.ReadCloser, error) {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	if content, ok := mfs.files[name]; ok {
+		return io.NopCloser(bytes.NewReader(content.Bytes())), nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (mfs *memFileSystem) Stat(name string) (os.FileInfo, error) {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	if content, ok := mfs.files[name]; ok {
+		return memFileInfo{size: int64(content.Len()), modTime: mfs.modTimes[name]}, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func (mfs *memFileSystem) Rename(oldPath, newPath string) error {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	content, ok := mfs.files[oldPath]
+	if !ok {
+		return fs.ErrNotExist
+	}
+	mfs.files[newPath] = content
+	mfs.modTimes[newPath] = mfs.modTimes[oldPath]
+	delete(mfs.files, oldPath)
+	delete(mfs.modTimes, oldPath)
+	return nil
+}
+
+func (mfs *memFileSystem) Remove(name string) error {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	if _, ok := mfs.files[name]; !ok {
+		return fs.ErrNotExist
+	}
+	delete(mfs.files, name)
+	delete(mfs.modTimes, name)
+	return nil
+}
+
+func (mfs *memFileSystem) names() []string {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	var names []string
+	for name := range mfs.files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (mfs *memFileSystem) content(name string) string {
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	return mfs.files[name].String()
+}
+
+func TestRotatingFile_Size(t *testing.T) {
+	mfs := newMemFileSystem()
+	rf := NewRotatingFile("app.log", RotationPolicy{MaxSize: 10, MaxBackups: 2}).WithFileSystem(mfs)
+	for _, s := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := io.WriteString(rf, s); err != nil {
+			t.Fatalf("RotatingFile.Write() error = %v", err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Errorf("RotatingFile.Close() error = %v", err)
+	}
+	if got, want := mfs.names(), []string{"app.log", "app.log.1", "app.log.2"}; !slices.Equal(got, want) {
+		t.Fatalf("RotatingFile files = %v, want %v", got, want)
+	}
+	for name, want := range map[string]string{"app.log": "dddddd\n", "app.log.1": "cccccc\n", "app.log.2": "bbbbbb\n"} {
+		if got := mfs.content(name); got != want {
+			t.Errorf("RotatingFile %s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestRotatingFile_AgeAndCompression(t *testing.T) {
+	mfs := newMemFileSystem()
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	rf := NewRotatingFile("app.log", RotationPolicy{MaxAge: time.Hour, MaxBackups: 1, Compress: true}).
+		WithFileSystem(mfs).
+		WithClock(clock)
+	_, _ = io.WriteString(rf, "first\n")
+	now = now.Add(time.Hour)
+	_, _ = io.WriteString(rf, "second\n")
+	now = now.Add(30 * time.Minute)
+	_, _ = io.WriteString(rf, "third\n")
+	now = now.Add(30 * time.Minute)
+	_, _ = io.WriteString(rf, "fourth\n")
+	if got, want := mfs.names(), []string{"app.log", "app.log.1.gz"}; !slices.Equal(got, want) {
+		t.Fatalf("RotatingFile files = %v, want %v", got, want)
+	}
+	if got := mfs.content("app.log"); got != "fourth\n" {
+		t.Errorf("RotatingFile app.log = %q", got)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader([]byte(mfs.content("app.log.1.gz"))))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	if got, _ := io.ReadAll(reader); string(got) != "second\nthird\n" {
+		t.Errorf("RotatingFile app.log.1.gz = %q", got)
+	}
+}
+
+func TestRotatingFile_NoBackups(t *testing.T) {
+	mfs := newMemFileSystem()
+	rf := NewRotatingFile("app.log", RotationPolicy{}).WithFileSystem(mfs)
+	_, _ = io.WriteString(rf, "first\n")
+	if err := rf.Rotate(); err != nil {
+		t.Errorf("RotatingFile.Rotate() error = %v", err)
+	}
+	_, _ = io.WriteString(rf, "second\n")
+	if got, want := mfs.names(), []string{"app.log"}; !slices.Equal(got, want) {
+		t.Fatalf("RotatingFile files = %v, want %v", got, want)
+	}
+	if got := mfs.content("app.log"); got != "second\n" {
+		t.Errorf("RotatingFile app.log = %q", got)
+	}
+}
+
+func TestRotatingFile_Errors(t *testing.T) {
+	mfs := newMemFileSystem()
+	mfs.failOpens = true
+	rf := NewRotatingFile("app.log", RotationPolicy{MaxSize: 1}).WithFileSystem(mfs)
+	if _, err := io.WriteString(rf, "x"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("RotatingFile.Write() error = %v, want %v", err, fs.ErrPermission)
+	}
+	mfs.failOpens = false
+	_, _ = io.WriteString(rf, "x")
+	mfs.failOpens = true
+	if _, err := io.WriteString(rf, "y"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("RotatingFile.Write() error = %v, want %v", err, fs.ErrPermission)
+	}
+	mfs.failOpens = false
+	mfs.failWrites = true
+	if _, err := io.WriteString(rf, "z"); err == nil {
+		t.Errorf("RotatingFile.Write() expected error")
+	}
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	mfs := newMemFileSystem()
+	rf := NewRotatingFile("app.log", RotationPolicy{}).WithFileSystem(mfs)
+	_, _ = io.WriteString(rf, "first\n")
+	_ = mfs.Rename("app.log", "app.log.moved")
+	if err := rf.Reopen(); err != nil {
+		t.Errorf("RotatingFile.Reopen() error = %v", err)
+	}
+	_, _ = io.WriteString(rf, "second\n")
+	if got := mfs.content("app.log"); got != "second\n" {
+		t.Errorf("RotatingFile app.log = %q", got)
+	}
+	if mfs.opens != 2 {
+		t.Errorf("RotatingFile opened %d times, want 2", mfs.opens)
+	}
+}
+
+func TestRotatingFile_AgeSurvivesReopen(t *testing.T) {
+	mfs := newMemFileSystem()
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	rf := NewRotatingFile("app.log", RotationPolicy{MaxAge: time.Hour, MaxBackups: 1}).
+		WithFileSystem(mfs).
+		WithClock(clock)
+	_, _ = io.WriteString(rf, "first\n")
+	now = now.Add(45 * time.Minute)
+	if err := rf.Reopen(); err != nil {
+		t.Errorf("RotatingFile.Reopen() error = %v", err)
+	}
+	_, _ = io.WriteString(rf, "second\n")
+	now = now.Add(15 * time.Minute)
+	_, _ = io.WriteString(rf, "third\n")
+	if got := mfs.content("app.log"); got != "third\n" {
+		t.Errorf("RotatingFile app.log = %q", got)
+	}
+	if got := mfs.content("app.log.1"); got != "first\nsecond\n" {
+		t.Errorf("RotatingFile app.log.1 = %q", got)
+	}
+}
+
+func TestRotatingFile_AgeOfExistingFile(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		modTimes map[string]time.Time
+		backup   string
+		rotates  bool
+	}{
+		"fresh file": {
+			modTimes: map[string]time.Time{"app.log": now.Add(-30 * time.Minute)},
+			rotates:  false,
+		},
+		"stale file": {
+			modTimes: map[string]time.Time{"app.log": now.Add(-2 * time.Hour)},
+			rotates:  true,
+		},
+		"recently written file with stale backup": {
+			modTimes: map[string]time.Time{"app.log": now.Add(-time.Minute), "app.log.1": now.Add(-2 * time.Hour)},
+			backup:   "app.log.1",
+			rotates:  true,
+		},
+		"recently written file with fresh compressed backup": {
+			modTimes: map[string]time.Time{"app.log": now.Add(-time.Minute), "app.log.1.gz": now.Add(-30 * time.Minute)},
+			backup:   "app.log.1.gz",
+			rotates:  false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mfs := newMemFileSystem()
+			mfs.files["app.log"] = bytes.NewBufferString("old\n")
+			if tt.backup != "" {
+				mfs.files[tt.backup] = bytes.NewBufferString("older\n")
+			}
+			mfs.modTimes = tt.modTimes
+			rf := NewRotatingFile("app.log", RotationPolicy{MaxAge: time.Hour, MaxBackups: 2}).
+				WithFileSystem(mfs).
+				WithClock(func() time.Time { return now })
+			_, _ = io.WriteString(rf, "new\n")
+			want := "old\nnew\n"
+			if tt.rotates {
+				want = "new\n"
+			}
+			if got := mfs.content("app.log"); got != want {
+				t.Errorf("RotatingFile app.log = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRotatingFileLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rfl := NewRotatingFileLogger(NewRotatingFile(path, RotationPolicy{MaxSize: 100, MaxBackups: 1}))
+	rfl.WithClock(fixedClock)
+	rfl.Info("first message", map[string]any{"n": 1})
+	rfl.Info("second message", map[string]any{"n": 2})
+	if err := rfl.Close(); err != nil {
+		t.Errorf("RotatingFileLogger.Close() error = %v", err)
+	}
+	if rfl.File() == nil {
+		t.Errorf("RotatingFileLogger.File() = nil")
+	}
+	current, _ := os.ReadFile(path)
+	backup, _ := os.ReadFile(path + ".1")
+	if want := `{"time":"2026-10-18T09:15:02Z","level":"info","msg":"second message","fields":{"n":2}}` + "\n"; string(current) != want {
+		t.Errorf("RotatingFileLogger current file = %q, want %q", current, want)
+	}
+	if want := `{"time":"2026-10-18T09:15:02Z","level":"info","msg":"first message","fields":{"n":1}}` + "\n"; string(backup) != want {
+		t.Errorf("RotatingFileLogger backup file = %q, want %q", backup, want)
+	}
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// SamplingPolicy specifies how a SamplingLogger samples log messages.
+	SamplingPolicy struct {
+		// Interval is the length of a sampling window; zero means one second
+		Interval time.Duration
+		// First is the number of log messages with the same key that are
+		// passed on in each window
+		First int
+		// Thereafter specifies that, after the first First log messages with
+		// the same key in a window, every Thereafter'th log message is passed
+		// on; zero means that none of them are
+		Thereafter int
+		// Key returns the key used to decide whether log messages are similar;
+		// nil means that log messages with the same level and message are
+		// similar, regardless of their fields
+		Key func(l Level, msg string, fields map[string]any) string
+	}
+
+	// SamplingLogger is a Logger that limits how many similar log messages
+	// are passed on to another Logger: in each window, it passes on the first
+	// few similar log messages, and then a sample of the rest, suppressing
+	// the others. Panic and fatal log messages are never suppressed.
+	//
+	// For each key with suppressed log messages, the SamplingLogger passes on
+	// a summary log message, such as "suppressed 12345 similar messages", at
+	// the same level as the suppressed log messages, once the window in which
+	// they were suppressed has ended. A background goroutine checks for ended
+	// windows once per Interval, so summaries are passed on even if no
+	// further log messages arrive; they are also passed on with the next log
+	// message received after the window ends, and when Flush is called.
+	// Because summaries may be passed on from the background goroutine, the
+	// other Logger must be safe for concurrent use.
+	//
+	// Call Close to stop the background goroutine; Close passes on any
+	// pending summaries.
+	SamplingLogger struct {
+		logger    Logger
+		policy    SamplingPolicy
+		clock     Clock
+		lock      sync.Mutex
+		samples   map[string]*sample
+		lastSweep time.Time
+		stop      chan struct{}
+		stopOnce  sync.Once
+		done      chan struct{}
+	}
+
+	sample struct {
+		windowStart time.Time
+		count       int
+		suppressed  int
+		level       Level
+		msg         string
+	}
+)
+
+// NewSamplingLogger returns a SamplingLogger that passes sampled log messages
+// to l, and starts its background goroutine.
+func NewSamplingLogger(l Logger, policy SamplingPolicy) *SamplingLogger {
+	if policy.Interval <= 0 {
+		policy.Interval = time.Second
+	}
+	sl := &SamplingLogger{
+		logger:  l,
+		policy:  policy,
+		samples: map[string]*sample{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go sl.run(time.NewTicker(policy.Interval))
+	return sl
+}
+
+// WithClock sets the Clock that the SamplingLogger uses to determine sampling
+// windows; it returns the SamplingLogger so that calls can be chained onto
+// NewSamplingLogger(). The background goroutine checks for ended windows in
+// real time, once per Interval, but it uses the Clock to decide which windows
+// have ended.
+func (sl *SamplingLogger) WithClock(c Clock) *SamplingLogger {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+	sl.clock = c
+	return sl
+}
+
+func (sl *SamplingLogger) run(ticker *time.Ticker) {
+	defer close(sl.done)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sl.stop:
+			return
+		case <-ticker.C:
+			sl.lock.Lock()
+			now := sl.clock.now()
+			summaries := sl.sweep(now, false)
+			sl.lastSweep = now
+			sl.lock.Unlock()
+			sl.emit(summaries)
+		}
+	}
+}
+
+// Close stops the background goroutine and then passes on a summary for every
+// key with suppressed log messages, as Flush does; it always returns nil.
+func (sl *SamplingLogger) Close() error {
+	sl.stopOnce.Do(func() {
+		close(sl.stop)
+	})
+	<-sl.done
+	sl.Flush()
+	return nil
+}
+
+// Trace samples a trace log message.
+func (sl *SamplingLogger) Trace(msg string, fields map[string]any) {
+	sl.log(Trace, msg, fields)
+}
+
+// Debug samples a debug log message.
+func (sl *SamplingLogger) Debug(msg string, fields map[string]any) {
+	sl.log(Debug, msg, fields)
+}
+
+// Info samples an info log message.
+func (sl *SamplingLogger) Info(msg string, fields map[string]any) {
+	sl.log(Info, msg, fields)
+}
+
+// Warning samples a warning log message.
+func (sl *SamplingLogger) Warning(msg string, fields map[string]any) {
+	sl.log(Warning, msg, fields)
+}
+
+// Error samples an error log message.
+func (sl *SamplingLogger) Error(msg string, fields map[string]any) {
+	sl.log(Error, msg, fields)
+}
+
+// Panic passes on any pending summaries and the panic log message.
+func (sl *SamplingLogger) Panic(msg string, fields map[string]any) {
+	sl.Flush()
+	sl.logger.Panic(msg, fields)
+}
+
+// Fatal passes on any pending summaries and the fatal log message.
+func (sl *SamplingLogger) Fatal(msg string, fields map[string]any) {
+	sl.Flush()
+	sl.logger.Fatal(msg, fields)
+}
+
+// Flush passes on a summary for every key with suppressed log messages,
+// whether or not its window has ended.
+func (sl *SamplingLogger) Flush() {
+	sl.lock.Lock()
+	summaries := sl.sweep(time.Time{}, true)
+	sl.lock.Unlock()
+	sl.emit(summaries)
+}
+
+func (sl *SamplingLogger) log(l Level, msg string, fields map[string]any) {
+	key := sl.key(l, msg, fields)
+	sl.lock.Lock()
+	now := sl.clock.now()
+	var summaries []LogEntry
+	if now.Sub(sl.lastSweep) >= sl.policy.Interval {
+		summaries = sl.sweep(now, false)
+		sl.lastSweep = now
+	}
+	s, ok := sl.samples[key]
+	if !ok || now.Sub(s.windowStart) >= sl.policy.Interval {
+		if ok && s.suppressed > 0 {
+			summaries = append(summaries, s.summary())
+		}
+		s = &sample{windowStart: now, level: l, msg: msg}
+		sl.samples[key] = s
+	}
+	s.count++
+	pass := s.count <= sl.policy.First ||
+		(sl.policy.Thereafter > 0 && (s.count-sl.policy.First)%sl.policy.Thereafter == 0)
+	if !pass {
+		s.suppressed++
+	}
+	sl.lock.Unlock()
+	sl.emit(summaries)
+	if pass {
+		logAt(sl.logger, l, msg, fields)
+	}
+}
+
+func (sl *SamplingLogger) key(l Level, msg string, fields map[string]any) string {
+	if sl.policy.Key != nil {
+		return sl.policy.Key(l, msg, fields)
+	}
+	return fmt.Sprintf("%d:%s", l, msg)
+}
+
+// sweep collects summaries for the keys whose windows have ended (or for all
+// keys, if all is true), in key order, and forgets those keys; it must be
+// called with the lock held
+func (sl *SamplingLogger) sweep(now time.Time, all bool) []LogEntry {
+	var summaries []LogEntry
+	for _, key := range slices.Sorted(maps.Keys(sl.samples)) {
+		s := sl.samples[key]
+		if all || now.Sub(s.windowStart) >= sl.policy.Interval {
+			if s.suppressed > 0 {
+				summaries = append(summaries, s.summary())
+			}
+			if all {
+				s.suppressed = 0
+			} else {
+				delete(sl.samples, key)
+			}
+		}
+	}
+	return summaries
+}
+
+func (sl *SamplingLogger) emit(summaries []LogEntry) {
+	for _, summary := range summaries {
+		logAt(sl.logger, summary.Level, summary.Msg, summary.Fields)
+	}
+}
+
+func (s *sample) summary() LogEntry {
+	return LogEntry{
+		Level: s.level,
+		Msg:   fmt.Sprintf("suppressed %d similar messages", s.suppressed),
+		Fields: map[string]any{
+			"message":    s.msg,
+			"suppressed": s.suppressed,
+		},
+	}
+}
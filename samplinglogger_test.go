@@ -0,0 +1,116 @@
+package output_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/majohn-r/output"
+)
+
+func TestSamplingLogger(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	rl := output.NewRecordingLogger()
+	sl := output.NewSamplingLogger(rl, output.SamplingPolicy{First: 2, Thereafter: 3}).WithClock(clock)
+	defer func() {
+		_ = sl.Close()
+	}()
+	for range 9 {
+		sl.Warning("disk low", nil)
+	}
+	sl.Info("other", nil)
+	now = now.Add(time.Second)
+	sl.Error("next window", nil)
+	sl.Warning("disk low", nil)
+	want := "" +
+		"level='warning'  msg='disk low'\n" + // 1
+		"level='warning'  msg='disk low'\n" + // 2
+		"level='warning'  msg='disk low'\n" + // 5
+		"level='warning'  msg='disk low'\n" + // 8
+		"level='info'  msg='other'\n" +
+		"level='warning' message='disk low' suppressed='5' msg='suppressed 5 similar messages'\n" +
+		"level='error'  msg='next window'\n" +
+		"level='warning'  msg='disk low'\n"
+	if got := rl.String(); got != want {
+		t.Errorf("SamplingLogger wrote %q, want %q", got, want)
+	}
+}
+
+func TestSamplingLogger_Flush(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	ml := output.NewMockLogger(t).
+		Expect(output.Trace, "t", nil).
+		Expect(output.Debug, "d", nil).
+		Expect(output.Info, "i", nil).
+		Expect(output.Debug, "suppressed 2 similar messages", map[string]any{"message": "d", "suppressed": 2}).
+		Expect(output.Info, "suppressed 1 similar messages", map[string]any{"message": "i", "suppressed": 1}).
+		Expect(output.Fatal, "f", nil).
+		Expect(output.Panic, "p", nil).
+		Expect(output.Error, "keyed", map[string]any{"id": 1}).
+		Expect(output.Error, "keyed", map[string]any{"id": 2})
+	sl := output.NewSamplingLogger(ml, output.SamplingPolicy{
+		Interval: time.Minute,
+		First:    1,
+		Key: func(l output.Level, msg string, fields map[string]any) string {
+			if id, ok := fields["id"]; ok {
+				return msg + string(rune('0'+id.(int)))
+			}
+			return msg
+		},
+	}).WithClock(clock)
+	defer func() {
+		_ = sl.Close()
+	}()
+	sl.Trace("t", nil)
+	sl.Debug("d", nil)
+	sl.Debug("d", nil)
+	sl.Debug("d", nil)
+	sl.Info("i", nil)
+	sl.Info("i", nil)
+	sl.Fatal("f", nil)
+	sl.Panic("p", nil)
+	sl.Flush()
+	sl.Error("keyed", map[string]any{"id": 1})
+	sl.Error("keyed", map[string]any{"id": 2})
+	ml.AssertExpectations()
+}
+
+func TestSamplingLogger_Periodic(t *testing.T) {
+	ml := output.NewMockLogger(t).
+		Expect(output.Warning, "disk low", nil).
+		Expect(output.Warning, "suppressed 3 similar messages", map[string]any{"message": "disk low", "suppressed": 3})
+	sl := output.NewSamplingLogger(ml, output.SamplingPolicy{Interval: 10 * time.Millisecond, First: 1})
+	for range 4 {
+		sl.Warning("disk low", nil)
+	}
+	// no further log messages arrive; the summary is passed on regardless,
+	// before Close is called
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ml.Calls()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := len(ml.Calls()); got != 2 {
+		t.Errorf("SamplingLogger wrote %d log messages before Close(), want 2", got)
+	}
+	if err := sl.Close(); err != nil {
+		t.Errorf("SamplingLogger.Close() error = %v", err)
+	}
+	if err := sl.Close(); err != nil {
+		t.Errorf("SamplingLogger.Close() error = %v", err)
+	}
+	ml.AssertExpectations()
+}
+
+func TestSamplingLogger_Close(t *testing.T) {
+	rl := output.NewRecordingLogger()
+	sl := output.NewSamplingLogger(rl, output.SamplingPolicy{Interval: time.Hour, First: 1})
+	sl.Info("busy", nil)
+	sl.Info("busy", nil)
+	_ = sl.Close()
+	want := "level='info'  msg='busy'\n" +
+		"level='info' message='busy' suppressed='1' msg='suppressed 1 similar messages'\n"
+	if got := rl.String(); got != want {
+		t.Errorf("SamplingLogger.Close() wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package output
+
+// ReopenOnSIGHUP does nothing, as this platform has no SIGHUP; the returned
+// function also does nothing.
+func (rf *RotatingFile) ReopenOnSIGHUP() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package output
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ReopenOnSIGHUP makes the RotatingFile reopen its file whenever the process
+// receives SIGHUP, which is how logrotate and similar tools signal that they
+// have moved the file; call the returned function to stop.
+func (rf *RotatingFile) ReopenOnSIGHUP() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				_ = rf.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package output
+
+import (
+	"io"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_ReopenOnSIGHUP(t *testing.T) {
+	mfs := newMemFileSystem()
+	rf := NewRotatingFile("app.log", RotationPolicy{}).WithFileSystem(mfs)
+	stop := rf.ReopenOnSIGHUP()
+	defer stop()
+	_, _ = io.WriteString(rf, "first\n")
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("syscall.Kill() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rf.lock.Lock()
+		closed := rf.file == nil
+		rf.lock.Unlock()
+		if closed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("RotatingFile was not reopened after SIGHUP")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, _ = io.WriteString(rf, "second\n")
+	if mfs.opens != 2 {
+		t.Errorf("RotatingFile opened %d times, want 2", mfs.opens)
+	}
+}
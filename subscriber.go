@@ -0,0 +1,121 @@
+package output
+
+import (
+	"slices"
+	"sync"
+)
+
+type (
+	// EventFilter selects the Events that a subscriber is notified of; see
+	// Subscribe.
+	EventFilter func(Event) bool
+
+	// subscribers is the set of callbacks registered by Subscribe; its zero
+	// value is ready to use
+	subscribers struct {
+		lock   sync.Mutex
+		nextID uint64
+		list   []subscription
+	}
+
+	subscription struct {
+		id       uint64
+		filter   EventFilter
+		callback func(Event) error
+	}
+)
+
+// OnChannel returns an EventFilter that selects Events on the specified
+// Channel.
+func OnChannel(c Channel) EventFilter {
+	return func(e Event) bool {
+		return e.Channel == c
+	}
+}
+
+// OnLogLevel returns an EventFilter that selects log Events at the specified
+// level or more severe, e.g., OnLogLevel(Warning) selects warning, error,
+// panic, and fatal log Events.
+func OnLogLevel(l Level) EventFilter {
+	return func(e Event) bool {
+		return e.Channel == LogChannel && e.Level <= l
+	}
+}
+
+func (s *subscribers) add(filter EventFilter, callback func(Event) error) (unsubscribe func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.nextID++
+	id := s.nextID
+	s.list = append(s.list, subscription{id: id, filter: filter, callback: callback})
+	return func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.list = slices.DeleteFunc(s.list, func(sub subscription) bool {
+			return sub.id == id
+		})
+	}
+}
+
+// notify calls the callbacks whose filters select e; the list is copied first,
+// so that a callback can unsubscribe
+func (s *subscribers) notify(e Event) {
+	s.lock.Lock()
+	list := slices.Clone(s.list)
+	s.lock.Unlock()
+	for _, sub := range list {
+		if sub.filter == nil || sub.filter(e) {
+			sub.call(e)
+		}
+	}
+}
+
+// call calls the subscription's callback, discarding any error it returns or
+// panic it raises
+func (sub subscription) call(e Event) {
+	defer func() {
+		_ = recover()
+	}()
+	_ = sub.callback(e)
+}
+
+// Subscribe registers callback to be called after each Event selected by
+// filter (or every Event, if filter is nil) has been written; Events are seen
+// as they emerge from any Interceptors. Panic and fatal log Events are the
+// exception: because the Logger may panic or exit the program, callbacks see
+// them before they are passed to the Logger. Log Events discarded because of
+// the Bus's log level (see WithLogLevel) are not seen. Callbacks are called in
+// the order in which they were registered. An error returned by a callback, or
+// a panic raised by one, is discarded, so that a faulty callback cannot
+// disrupt output. Call the returned function to unsubscribe.
+func (b *bus) Subscribe(filter EventFilter, callback func(Event) error) (unsubscribe func()) {
+	return b.subscribers.add(filter, callback)
+}
+
+// Subscribe registers callback to be called after each Event selected by
+// filter (or every Event, if filter is nil) has been recorded; it behaves as
+// the Subscribe function of the Bus implementations returned by NewBus does.
+// Call the returned function to unsubscribe.
+func (r *Recorder) Subscribe(filter EventFilter, callback func(Event) error) (unsubscribe func()) {
+	return r.subscribers.add(filter, callback)
+}
+
+// Subscribe registers callback with b or, if b is a wrapper (a Bus with an
+// Unwrap() Bus function), with the Bus that it wraps; see the Subscribe
+// function of the Bus implementations returned by NewBus. ok is false, and
+// unsubscribe does nothing, if no Bus supporting subscriptions is found.
+func Subscribe(b Bus, filter EventFilter, callback func(Event) error) (unsubscribe func(), ok bool) {
+	for b != nil {
+		if subscribable, found := b.(interface {
+			Subscribe(EventFilter, func(Event) error) func()
+		}); found {
+			return subscribable.Subscribe(filter, callback), true
+		}
+		wrapper, found := b.(interface{ Unwrap() Bus })
+		if !found {
+			break
+		}
+		b = wrapper.Unwrap()
+	}
+	return func() {}, false
+}
@@ -0,0 +1,95 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestRecorder_Subscribe(t *testing.T) {
+	o := output.NewRecorder()
+	var all, warnings, errorLines []string
+	unsubscribeAll := o.Subscribe(nil, func(e output.Event) error {
+		// the event has already been written
+		all = append(all, fmt.Sprintf("%s %q %d", e.Channel, e.Text, len(o.ConsoleOutput())))
+		return nil
+	})
+	o.Subscribe(output.OnLogLevel(output.Warning), func(e output.Event) error {
+		warnings = append(warnings, e.Level.String()+" "+e.Text)
+		return errors.New("ignored")
+	})
+	o.Subscribe(output.OnChannel(output.ErrorChannel), func(e output.Event) error {
+		errorLines = append(errorLines, e.Text)
+		panic("bad callback")
+	})
+	o.ConsolePrintln("hello")
+	o.ErrorPrintf("%d problems\n", 2)
+	o.Log(output.Info, "fine", nil)
+	o.Log(output.Error, "broken", nil)
+	unsubscribeAll()
+	unsubscribeAll()
+	o.ConsolePrintln("unobserved")
+	o.Log(output.Warning, "careful", nil)
+	o.Log(output.Level(99), "invalid", nil)
+	o.Report(t, "Subscribe()", output.WantedRecording{
+		Console: "hello\nunobserved\n",
+		Error: "2 problems\n" +
+			"Programming error: call to Recorder.Log() with invalid level value 99; message: 'invalid', args: 'map[]'.\n",
+		Log: "level='info'  msg='fine'\nlevel='error'  msg='broken'\nlevel='warning'  msg='careful'\n",
+	})
+	wantAll := `[console "hello\n" 6 error "2 problems\n" 6 log "fine" 6 log "broken" 6]`
+	if got := fmt.Sprint(all); got != wantAll {
+		t.Errorf("Subscribe() saw %s, want %s", got, wantAll)
+	}
+	if got, want := fmt.Sprint(warnings), "[error broken warning careful]"; got != want {
+		t.Errorf("Subscribe() saw %s, want %s", got, want)
+	}
+	if len(errorLines) != 2 {
+		t.Errorf("Subscribe() saw %d error lines, want 2", len(errorLines))
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	console := &bytes.Buffer{}
+	o := output.NewBus(
+		output.WithConsoleWriter(console),
+		output.WithInterceptors(func(e output.Event, next func(output.Event)) {
+			e.Text = "> " + e.Text
+			next(e)
+		}),
+	)
+	var seen []string
+	unsubscribe, ok := output.Subscribe(output.NewCapturingBus(o, &bytes.Buffer{}), nil, func(e output.Event) error {
+		seen = append(seen, e.Text)
+		return nil
+	})
+	if !ok {
+		t.Fatalf("Subscribe() failed")
+	}
+	o.ConsolePrintln("one")
+	unsubscribe()
+	o.ConsolePrintln("two")
+	if got, want := fmt.Sprint(seen), "[> one\n]"; got != want {
+		t.Errorf("Subscribe() saw %q, want %q", got, want)
+	}
+	if got := console.String(); got != "> one\n> two\n" {
+		t.Errorf("Subscribe() console output = %q", got)
+	}
+	unsubscribe, ok = output.Subscribe(output.NewPartialBus(output.NewRecorder(), nil), nil, nil)
+	if !ok {
+		t.Errorf("Subscribe() did not find the Recorder behind a partial Bus")
+	}
+	unsubscribe()
+	if unsubscribe, ok = output.Subscribe(&unsubscribableBus{}, nil, nil); ok {
+		t.Errorf("Subscribe() succeeded on a Bus without subscriptions")
+	}
+	unsubscribe()
+}
+
+// unsubscribableBus is a Bus without subscriptions
+type unsubscribableBus struct {
+	output.Bus
+}
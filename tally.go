@@ -0,0 +1,245 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// Tally counts the warning and error messages emitted through a Bus, so
+	// that a program that carries on after recoverable errors can summarize
+	// them and choose an exit code when it finishes.
+	//
+	// A message logged at the Warning level counts as a warning; a message
+	// logged at the Error, Panic, or Fatal level, and every message printed by
+	// ErrorPrintf or ErrorPrintln, counts as an error. A problem that is both
+	// printed and logged, as ReportError does, is therefore counted twice.
+	// Each call counts once, regardless of newlines: an error line built by
+	// several ErrorPrintf calls, such as ErrorPrintf("a") followed by
+	// ErrorPrintf("b\n"), counts as several errors. Writes made directly to
+	// the writer returned by ErrorWriter are not counted.
+	//
+	// A Tally always keeps the counts and the most severe level seen; it only
+	// keeps the messages themselves if asked to (see Retain), so that a
+	// long-running program does not accumulate them. A Recorder's Tally keeps
+	// every message.
+	//
+	// The Bus implementations returned by NewBus, NewDefaultBus, and
+	// NewCustomBus, and Recorder, keep a Tally; use TallyOf to find it. The
+	// functions of a nil *Tally behave as if nothing has been counted.
+	Tally struct {
+		lock         sync.Mutex
+		warningCount int
+		errorCount   int
+		retain       int
+		warnings     []LogEntry
+		errors       []LogEntry
+		highest      Level
+		counted      bool
+	}
+)
+
+// DefaultExitCodes maps the highest severity counted by a Tally to the exit
+// code returned by Tally.ExitCode(nil).
+var DefaultExitCodes = map[Level]int{
+	Warning: 0,
+	Error:   1,
+	Panic:   1,
+	Fatal:   1,
+}
+
+func (t *Tally) countLog(l Level, msg string, fields map[string]any) {
+	if l > Warning {
+		return
+	}
+	t.count(LogEntry{Level: l, Msg: msg, Fields: fields})
+}
+
+func (t *Tally) countErrorOutput(msg string) {
+	t.count(LogEntry{Level: Error, Msg: strings.TrimSuffix(msg, "\n")})
+}
+
+func (t *Tally) count(entry LogEntry) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if entry.Level == Warning {
+		t.warningCount++
+		t.warnings = t.keep(t.warnings, entry)
+	} else {
+		t.errorCount++
+		t.errors = t.keep(t.errors, entry)
+	}
+	if !t.counted || entry.Level < t.highest {
+		t.highest = entry.Level
+		t.counted = true
+	}
+}
+
+// keep appends entry to entries, as permitted by the retention limit; the
+// entry's fields are copied, as the caller may reuse them
+func (t *Tally) keep(entries []LogEntry, entry LogEntry) []LogEntry {
+	if t.retain == 0 {
+		return entries
+	}
+	entry.Fields = maps.Clone(entry.Fields)
+	entries = append(entries, entry)
+	if t.retain > 0 && len(entries) > t.retain {
+		entries = slices.Delete(entries, 0, len(entries)-t.retain)
+	}
+	return entries
+}
+
+// Retain sets how many warning messages, and how many error messages, the
+// Tally keeps: when n is positive, it keeps the n most recent of each; when n
+// is negative, it keeps all of them; and when n is zero (the default for a Bus),
+// it keeps none. Messages already kept beyond the new limit are discarded. It
+// returns the Tally so that calls can be chained onto TallyOf().
+func (t *Tally) Retain(n int) *Tally {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.retain = n
+	switch {
+	case n == 0:
+		t.warnings = nil
+		t.errors = nil
+	case n > 0:
+		t.warnings = t.warnings[max(len(t.warnings)-n, 0):]
+		t.errors = t.errors[max(len(t.errors)-n, 0):]
+	}
+	return t
+}
+
+// Warnings returns the warning messages kept so far (see Retain); only the
+// Level, Msg, and Fields of a logged message are kept.
+func (t *Tally) Warnings() []LogEntry {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]LogEntry(nil), t.warnings...)
+}
+
+// Errors returns the error messages kept so far (see Retain); a message
+// printed by ErrorPrintf or ErrorPrintln is kept as an Error level LogEntry
+// with no fields.
+func (t *Tally) Errors() []LogEntry {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]LogEntry(nil), t.errors...)
+}
+
+// Counts returns the number of error and warning messages counted so far.
+func (t *Tally) Counts() (errorCount, warningCount int) {
+	if t == nil {
+		return 0, 0
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.errorCount, t.warningCount
+}
+
+// Highest returns the most severe level counted so far; ok is false if nothing
+// has been counted.
+func (t *Tally) Highest() (l Level, ok bool) {
+	if t == nil {
+		return 0, false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.highest, t.counted
+}
+
+// Reset discards everything counted so far; the retention limit set by Retain
+// is unchanged.
+func (t *Tally) Reset() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.warningCount = 0
+	t.errorCount = 0
+	t.warnings = nil
+	t.errors = nil
+	t.highest = 0
+	t.counted = false
+}
+
+// Summary returns a one-line summary of the counts, such as "3 errors, 1
+// warning"; it returns an empty string if nothing has been counted.
+func (t *Tally) Summary() string {
+	errorCount, warningCount := t.Counts()
+	if errorCount == 0 && warningCount == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s, %s", plural(errorCount, "error"), plural(warningCount, "warning"))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// ExitCode maps the most severe level counted so far to a process exit code,
+// using codes, or DefaultExitCodes if codes is nil; it returns 0 if nothing has
+// been counted, or if codes has no entry for the most severe level.
+func (t *Tally) ExitCode(codes map[Level]int) int {
+	l, ok := t.Highest()
+	if !ok {
+		return 0
+	}
+	if codes == nil {
+		codes = DefaultExitCodes
+	}
+	return codes[l]
+}
+
+// Tally returns the Tally of the warning and error messages emitted through
+// the Bus.
+func (b *bus) Tally() *Tally {
+	return &b.tally
+}
+
+// Tally returns the Tally of the warning and error messages recorded by the
+// Recorder.
+func (r *Recorder) Tally() *Tally {
+	return &r.tally
+}
+
+// TallyOf returns the Tally kept by b or, if b is a wrapper (a Bus with an
+// Unwrap() Bus function, such as CapturingBus), by the Bus that it wraps; it
+// returns nil if there is no Tally to be found.
+func TallyOf(b Bus) *Tally {
+	for b != nil {
+		if tallier, ok := b.(interface{ Tally() *Tally }); ok {
+			return tallier.Tally()
+		}
+		wrapper, ok := b.(interface{ Unwrap() Bus })
+		if !ok {
+			break
+		}
+		b = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// PrintSummary writes the summary of b's Tally (see TallyOf), followed by a
+// newline, directly to b's error writer, so that the summary is not itself
+// counted; it writes nothing if nothing has been counted.
+func PrintSummary(b Bus) {
+	if summary := TallyOf(b).Summary(); summary != "" {
+		_, _ = fmt.Fprintln(b.ErrorWriter(), summary)
+	}
+}
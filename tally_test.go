@@ -0,0 +1,196 @@
+package output_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/majohn-r/output"
+)
+
+func TestTally(t *testing.T) {
+	o := output.NewRecorder()
+	tally := output.TallyOf(o)
+	if got := tally.Summary(); got != "" {
+		t.Errorf("Summary() = %q, want empty", got)
+	}
+	if got := tally.ExitCode(nil); got != 0 {
+		t.Errorf("ExitCode() = %d, want 0", got)
+	}
+	o.Log(output.Info, "not counted", nil)
+	o.Log(output.Warning, "disk low", map[string]any{"free": 10})
+	o.ConsolePrintln("not counted")
+	o.ErrorPrintf("cannot read %s\n", "a.txt")
+	o.Log(output.Warning, "slow", nil)
+	_, _ = o.ErrorWriter().Write([]byte("not counted\n"))
+	if l, ok := tally.Highest(); !ok || l != output.Error {
+		t.Errorf("Highest() = %v, %t, want error, true", l, ok)
+	}
+	o.ErrorPrintln("cannot write b.txt")
+	errorCount, warningCount := tally.Counts()
+	if errorCount != 2 || warningCount != 2 {
+		t.Errorf("Counts() = %d, %d, want 2, 2", errorCount, warningCount)
+	}
+	wantWarnings := []output.LogEntry{
+		{Level: output.Warning, Msg: "disk low", Fields: map[string]any{"free": 10}},
+		{Level: output.Warning, Msg: "slow"},
+	}
+	if got := tally.Warnings(); !reflect.DeepEqual(got, wantWarnings) {
+		t.Errorf("Warnings() = %v, want %v", got, wantWarnings)
+	}
+	wantErrors := []output.LogEntry{
+		{Level: output.Error, Msg: "cannot read a.txt"},
+		{Level: output.Error, Msg: "cannot write b.txt"},
+	}
+	if got := tally.Errors(); !reflect.DeepEqual(got, wantErrors) {
+		t.Errorf("Errors() = %v, want %v", got, wantErrors)
+	}
+	if got := tally.Summary(); got != "2 errors, 2 warnings" {
+		t.Errorf("Summary() = %q, want %q", got, "2 errors, 2 warnings")
+	}
+	if got := tally.ExitCode(map[output.Level]int{output.Error: 3}); got != 3 {
+		t.Errorf("ExitCode() = %d, want 3", got)
+	}
+	o.Reset()
+	if got := tally.Summary(); got != "" {
+		t.Errorf("Summary() after Reset() = %q, want empty", got)
+	}
+}
+
+func TestTally_ExitCode(t *testing.T) {
+	tests := map[string]struct {
+		levels []output.Level
+		want   int
+	}{
+		"nothing":  {want: 0},
+		"warning":  {levels: []output.Level{output.Warning}, want: 0},
+		"error":    {levels: []output.Level{output.Warning, output.Error}, want: 1},
+		"fatal":    {levels: []output.Level{output.Fatal, output.Warning}, want: 1},
+		"not seen": {levels: []output.Level{output.Trace, output.Debug, output.Info}, want: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := output.NewRecorder()
+			for _, l := range tt.levels {
+				o.Log(l, "message", nil)
+			}
+			if got := o.Tally().ExitCode(nil); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTally_nil(t *testing.T) {
+	var tally *output.Tally
+	tally.Reset()
+	if tally.Warnings() != nil || tally.Errors() != nil || tally.Summary() != "" || tally.ExitCode(nil) != 0 {
+		t.Errorf("nil *Tally reported counts")
+	}
+	nilBus := output.NewNilBus()
+	nilBus.ErrorPrintln("discarded")
+	if got := output.TallyOf(nilBus).Summary(); got != "" {
+		t.Errorf("TallyOf(NewNilBus()).Summary() = %q, want empty", got)
+	}
+	if got := output.TallyOf(nil); got != nil {
+		t.Errorf("TallyOf(nil) = %v, want nil", got)
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	console := &bytes.Buffer{}
+	errors := &bytes.Buffer{}
+	o := output.NewCustomBus(console, errors, output.NilLogger{})
+	wrapped := output.NewCapturingBus(o, &bytes.Buffer{})
+	output.PrintSummary(wrapped)
+	if errors.Len() != 0 {
+		t.Errorf("PrintSummary() wrote %q with nothing counted", errors.String())
+	}
+	wrapped.ErrorPrintln("oops")
+	wrapped.Log(output.Warning, "hmm", nil)
+	output.PrintSummary(wrapped)
+	if got, want := errors.String(), "oops\n1 error, 1 warning\n"; got != want {
+		t.Errorf("PrintSummary() wrote %q, want %q", got, want)
+	}
+	if got := output.TallyOf(wrapped).ExitCode(nil); got != 1 {
+		t.Errorf("ExitCode() = %d, want 1", got)
+	}
+	if errorCount, _ := output.TallyOf(o).Counts(); errorCount != 1 {
+		t.Errorf("PrintSummary() was counted as an error")
+	}
+}
+
+func TestTally_Retain(t *testing.T) {
+	o := output.NewBus(output.WithErrorWriter(&bytes.Buffer{}))
+	tally := output.TallyOf(o)
+	o.ErrorPrintln("not kept")
+	if got := tally.Errors(); got != nil {
+		t.Errorf("Errors() = %v, want nil by default", got)
+	}
+	tally.Retain(2)
+	fields := map[string]any{"attempt": 1}
+	o.Log(output.Warning, "first", fields)
+	fields["attempt"] = 2
+	o.Log(output.Warning, "second", fields)
+	o.Log(output.Warning, "third", nil)
+	want := []output.LogEntry{
+		{Level: output.Warning, Msg: "second", Fields: map[string]any{"attempt": 2}},
+		{Level: output.Warning, Msg: "third"},
+	}
+	if got := tally.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Warnings() = %v, want %v", got, want)
+	}
+	fields["attempt"] = 3
+	if got := tally.Warnings()[0].Fields["attempt"]; got != 2 {
+		t.Errorf("Warnings() kept the caller's map: attempt = %v", got)
+	}
+	if errorCount, warningCount := tally.Counts(); errorCount != 1 || warningCount != 3 {
+		t.Errorf("Counts() = %d, %d, want 1, 3", errorCount, warningCount)
+	}
+	tally.Retain(1)
+	if got := tally.Warnings(); len(got) != 1 || got[0].Msg != "third" {
+		t.Errorf("Warnings() after Retain(1) = %v", got)
+	}
+	tally.Retain(-1)
+	for range 5 {
+		o.ErrorPrintln("kept")
+	}
+	if got := len(tally.Errors()); got != 5 {
+		t.Errorf("Errors() kept %d messages, want 5", got)
+	}
+	tally.Retain(0)
+	if tally.Warnings() != nil || tally.Errors() != nil {
+		t.Errorf("Retain(0) kept messages")
+	}
+	var nilTally *output.Tally
+	if nilTally.Retain(3) != nil {
+		t.Errorf("Retain() on a nil *Tally returned non-nil")
+	}
+}
+
+// countingStringer counts how often it is formatted
+type countingStringer struct{ calls *int }
+
+func (cs countingStringer) String() string {
+	*cs.calls++
+	return "thing"
+}
+
+func TestTally_ErrorPrintfFormatsOnce(t *testing.T) {
+	tests := map[string]output.Bus{
+		"bus":      output.NewBus(output.WithErrorWriter(&bytes.Buffer{})),
+		"recorder": output.NewRecorder(),
+	}
+	for name, o := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			o.ErrorPrintf("bad %v\n", countingStringer{calls: &calls})
+			if calls != 1 {
+				t.Errorf("ErrorPrintf() formatted its argument %d times, want 1", calls)
+			}
+			if errorCount, _ := output.TallyOf(o).Counts(); errorCount != 1 {
+				t.Errorf("Counts() errors = %d, want 1", errorCount)
+			}
+		})
+	}
+}
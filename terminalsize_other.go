@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package output
+
+// platformTerminalSize always reports that the terminal size cannot be
+// determined, as this platform offers no way to find it
+func platformTerminalSize(_ uintptr) (width, height int, ok bool) {
+	return 0, 0, false
+}
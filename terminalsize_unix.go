@@ -0,0 +1,15 @@
+//go:build unix
+
+package output
+
+import "golang.org/x/sys/unix"
+
+// platformTerminalSize returns the width and height of the terminal open on
+// fd; ok is false if they cannot be determined
+func platformTerminalSize(fd uintptr) (width, height int, ok bool) {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, false
+	}
+	return int(ws.Col), int(ws.Row), true
+}
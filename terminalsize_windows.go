@@ -0,0 +1,15 @@
+//go:build windows
+
+package output
+
+import "golang.org/x/sys/windows"
+
+// platformTerminalSize returns the width and height of the console window open
+// on fd; ok is false if they cannot be determined
+func platformTerminalSize(fd uintptr) (width, height int, ok bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(fd), &info); err != nil {
+		return 0, 0, false
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, true
+}